@@ -3,14 +3,49 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DatabaseURL string
-	JWTSecret   string
-	Port        string
+	DatabaseURL     string
+	JWTSecret       string
+	Port            string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// Links in outgoing email point at the frontend
+	AppBaseURL           string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	InvitationTTL        time.Duration
+	JoinLinkTTL          time.Duration // Used when a join link is created without an explicit expiry
+
+	// What unverified accounts may do: "block" refuses login, "read_only" only allows reads
+	UnverifiedAccountPolicy string
+
+	// Mail delivery: "smtp" or "outbox" (writes to MailOutboxDir, or stdout when empty)
+	MailDriver    string
+	MailFrom      string
+	MailOutboxDir string
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+
+	// Attachment storage: "local" keeps blobs below StorageLocalDir
+	StorageDriver     string
+	StorageLocalDir   string
+	AttachmentMaxSize int64 // Bytes
+
+	// What deleting a task does to its subtasks: "cascade" deletes them, "orphan" detaches them,
+	// "block" refuses while any remain
+	SubtaskDeletePolicy string
+
+	// Moving a task with open blockers into a done status: "warn" allows it with a warning, "refuse" rejects it
+	BlockedCompletionPolicy string
 }
 
 var AppConfig *Config
@@ -23,9 +58,34 @@ func LoadConfig() {
 	}
 
 	AppConfig = &Config{
-		DatabaseURL: getEnv("DATABASE_URL", ""),
-		JWTSecret:   getEnv("JWT_SECRET", "default-secret-key"),
-		Port:        getEnv("PORT", "8080"),
+		DatabaseURL:     getEnv("DATABASE_URL", ""),
+		JWTSecret:       getEnv("JWT_SECRET", "default-secret-key"),
+		Port:            getEnv("PORT", "8080"),
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		AppBaseURL:           getEnv("APP_BASE_URL", "http://localhost:3000"),
+		PasswordResetTTL:     getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationTTL: getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		InvitationTTL:        getEnvDuration("INVITATION_TTL", 7*24*time.Hour),
+		JoinLinkTTL:          getEnvDuration("JOIN_LINK_TTL", 7*24*time.Hour),
+
+		UnverifiedAccountPolicy: getEnv("UNVERIFIED_ACCOUNT_POLICY", "read_only"),
+
+		MailDriver:    getEnv("MAIL_DRIVER", "outbox"),
+		MailFrom:      getEnv("MAIL_FROM", "NexBoard <no-reply@nexboard.local>"),
+		MailOutboxDir: getEnv("MAIL_OUTBOX_DIR", ""),
+		SMTPHost:      getEnv("SMTP_HOST", ""),
+		SMTPPort:      getEnv("SMTP_PORT", "587"),
+		SMTPUsername:  getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:  getEnv("SMTP_PASSWORD", ""),
+
+		StorageDriver:     getEnv("STORAGE_DRIVER", "local"),
+		StorageLocalDir:   getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+		AttachmentMaxSize: int64(getEnvInt("ATTACHMENT_MAX_SIZE_MB", 10)) << 20,
+
+		SubtaskDeletePolicy:     getEnv("SUBTASK_DELETE_POLICY", "orphan"),
+		BlockedCompletionPolicy: getEnv("BLOCKED_COMPLETION_POLICY", "warn"),
 	}
 
 	// Validate required environment variables
@@ -33,6 +93,32 @@ func LoadConfig() {
 		log.Fatal("DATABASE_URL is required")
 	}
 
+	if AppConfig.UnverifiedAccountPolicy != "block" && AppConfig.UnverifiedAccountPolicy != "read_only" {
+		log.Fatal("UNVERIFIED_ACCOUNT_POLICY must be either block or read_only")
+	}
+
+	if AppConfig.MailDriver == "smtp" && AppConfig.SMTPHost == "" {
+		log.Fatal("SMTP_HOST is required when MAIL_DRIVER=smtp")
+	}
+
+	if AppConfig.StorageDriver != "local" {
+		log.Fatal("STORAGE_DRIVER must be local")
+	}
+
+	if AppConfig.AttachmentMaxSize <= 0 {
+		log.Fatal("ATTACHMENT_MAX_SIZE_MB must be positive")
+	}
+
+	switch AppConfig.SubtaskDeletePolicy {
+	case "cascade", "orphan", "block":
+	default:
+		log.Fatal("SUBTASK_DELETE_POLICY must be one of cascade, orphan or block")
+	}
+
+	if AppConfig.BlockedCompletionPolicy != "warn" && AppConfig.BlockedCompletionPolicy != "refuse" {
+		log.Fatal("BLOCKED_COMPLETION_POLICY must be either warn or refuse")
+	}
+
 	if AppConfig.JWTSecret == "default-secret-key" {
 		log.Println("Warning: Using default JWT secret. Set JWT_SECRET environment variable for production")
 	}
@@ -45,3 +131,31 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvDuration parses a duration environment variable (e.g. "15m", "720h") with a fallback value
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid duration for %s (%q), using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// getEnvInt parses an integer environment variable with a fallback value
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid integer for %s (%q), using %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
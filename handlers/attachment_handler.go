@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+// multipartOverhead leaves room for boundaries and part headers on top of the file itself
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+}
+
+func NewAttachmentHandler(attachmentService *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{attachmentService: attachmentService}
+}
+
+// UploadAttachment accepts a multipart upload in the "file" field
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	maxSize := h.attachmentService.MaxSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.ErrorResponse(c, 413, fmt.Sprintf("file must be at most %d MB", maxSize>>20))
+			return
+		}
+		utils.ErrorResponseWithDetails(c, 422, "validation_error", "request validation failed", map[string]string{"file": "is required"})
+		return
+	}
+
+	attachment, err := h.attachmentService.UploadAttachment(userExtID.(string), taskExtID, file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 201, attachment)
+}
+
+// GetAttachments lists a task's attachments
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	attachments, err := h.attachmentService.GetAttachments(userExtID.(string), taskExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, attachments)
+}
+
+// DownloadAttachment streams the stored file back to a workspace member
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+	attachmentExtID := c.Param("attachment_ext_id")
+
+	attachment, blob, err := h.attachmentService.OpenAttachment(userExtID.(string), taskExtID, attachmentExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer blob.Close()
+
+	c.Header("Content-Type", attachment.MimeType)
+	c.Header("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(200)
+	if _, err := io.Copy(c.Writer, blob); err != nil {
+		log.Printf("Failed to stream attachment %s: %v", attachment.ExternalID, err)
+	}
+}
+
+// DeleteAttachment removes an attachment and its stored file
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+	attachmentExtID := c.Param("attachment_ext_id")
+
+	if err := h.attachmentService.DeleteAttachment(userExtID.(string), taskExtID, attachmentExtID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "attachment deleted successfully"})
+}
@@ -19,14 +19,13 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	user, err := h.authService.Register(&req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -37,27 +36,123 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	response, err := h.authService.Login(req.Email, req.Password)
 	if err != nil {
-		utils.ErrorResponse(c, 401, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, 200, response)
 }
 
+// RefreshToken exchanges a refresh token for a new token pair
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tokens, err := h.authService.RefreshToken(req.RefreshToken)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, tokens)
+}
+
+// Logout revokes the session behind a refresh token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "logged out successfully"})
+}
+
+// ForgotPassword sends a password reset link if the email is registered
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.ForgotPassword(req.Email); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "if the email is registered, a reset link has been sent"})
+}
+
+// ResetPassword sets a new password using a reset token
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.ResetPassword(&req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "password has been reset successfully"})
+}
+
+// VerifyEmail confirms an email address. The token may come from the query string (email link) or a JSON body.
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if c.Request.Method == "GET" {
+		req.Token = c.Query("token")
+		if req.Token == "" {
+			utils.ErrorResponseWithDetails(c, 422, "validation_error", "request validation failed", map[string]string{"token": "is required"})
+			return
+		}
+	} else if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.VerifyEmail(req.Token); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "email verified successfully"})
+}
+
+// ResendVerification sends a new verification link
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.ResendVerification(req.Email); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "if the account needs verification, a new link has been sent"})
+}
+
 // GetProfile retrieves the authenticated user's profile
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userExtID, _ := c.Get("user_external_id")
 
 	user, err := h.authService.GetProfile(userExtID.(string))
 	if err != nil {
-		utils.ErrorResponse(c, 404, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -67,8 +162,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 // UpdateProfile updates the authenticated user's profile
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	var req models.UpdateProfileRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -76,7 +170,57 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 
 	user, err := h.authService.UpdateProfile(userExtID.(string), &req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, user)
+}
+
+// ChangePassword updates the authenticated user's password and signs out other sessions
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userExtID, _ := c.Get("user_external_id")
+
+	if err := h.authService.ChangePassword(userExtID.(string), c.GetString("session_id"), &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "password changed successfully"})
+}
+
+// RequestEmailChange sends a confirmation link to the requested new address
+func (h *AuthHandler) RequestEmailChange(c *gin.Context) {
+	var req models.ChangeEmailRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userExtID, _ := c.Get("user_external_id")
+
+	if err := h.authService.RequestEmailChange(userExtID.(string), &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 202, gin.H{"message": "a confirmation link has been sent to the new email address"})
+}
+
+// ConfirmEmailChange applies a pending email change from its confirmation token
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	var req models.ConfirmEmailChangeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.authService.ConfirmEmailChange(req.Token)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
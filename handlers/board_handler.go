@@ -21,14 +21,13 @@ func (h *BoardHandler) CreateWorkspaceBoard(c *gin.Context) {
 	workspaceExtID := c.Param("external_id")
 
 	var req models.BoardRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	board, err := h.boardService.CreateBoard(userExtID.(string), workspaceExtID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -42,7 +41,7 @@ func (h *BoardHandler) GetWorkspaceBoards(c *gin.Context) {
 
 	boards, err := h.boardService.GetWorkspaceBoards(userExtID.(string), workspaceExtID)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -56,7 +55,7 @@ func (h *BoardHandler) GetBoard(c *gin.Context) {
 
 	board, err := h.boardService.GetBoard(userExtID.(string), boardExtID)
 	if err != nil {
-		utils.ErrorResponse(c, 404, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -69,14 +68,13 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 	boardExtID := c.Param("external_id")
 
 	var req models.BoardRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	board, err := h.boardService.UpdateBoard(userExtID.(string), boardExtID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -89,7 +87,7 @@ func (h *BoardHandler) DeleteBoard(c *gin.Context) {
 	boardExtID := c.Param("external_id")
 
 	if err := h.boardService.DeleteBoard(userExtID.(string), boardExtID); err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
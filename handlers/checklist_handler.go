@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type ChecklistHandler struct {
+	checklistService *services.ChecklistService
+}
+
+func NewChecklistHandler(checklistService *services.ChecklistService) *ChecklistHandler {
+	return &ChecklistHandler{checklistService: checklistService}
+}
+
+// CreateItem adds an item to a task's checklist
+func (h *ChecklistHandler) CreateItem(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	var req models.ChecklistItemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	item, err := h.checklistService.CreateItem(userExtID.(string), taskExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 201, item)
+}
+
+// GetItems lists a task's checklist in order
+func (h *ChecklistHandler) GetItems(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	items, err := h.checklistService.GetItems(userExtID.(string), taskExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, items)
+}
+
+// UpdateItem replaces a checklist item's fields
+func (h *ChecklistHandler) UpdateItem(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+	itemExtID := c.Param("item_ext_id")
+
+	var req models.ChecklistItemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	item, err := h.checklistService.UpdateItem(userExtID.(string), taskExtID, itemExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, item)
+}
+
+// DeleteItem removes a checklist item
+func (h *ChecklistHandler) DeleteItem(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+	itemExtID := c.Param("item_ext_id")
+
+	if err := h.checklistService.DeleteItem(userExtID.(string), taskExtID, itemExtID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "checklist item deleted successfully"})
+}
+
+// ReorderItems sets the order of a task's checklist
+func (h *ChecklistHandler) ReorderItems(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	var req models.ReorderChecklistRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	items, err := h.checklistService.ReorderItems(userExtID.(string), taskExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, items)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type CommentHandler struct {
+	commentService *services.CommentService
+}
+
+func NewCommentHandler(commentService *services.CommentService) *CommentHandler {
+	return &CommentHandler{commentService: commentService}
+}
+
+// CreateComment posts a comment on a task
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	var req models.TaskCommentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(userExtID.(string), taskExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 201, comment)
+}
+
+// GetComments lists a task's comments, newest first, paginated with ?page=&limit=
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	comments, err := h.commentService.GetComments(userExtID.(string), taskExtID, c.Query("page"), c.Query("limit"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, comments)
+}
+
+// UpdateComment edits the caller's own comment
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+	commentExtID := c.Param("comment_ext_id")
+
+	var req models.TaskCommentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(userExtID.(string), taskExtID, commentExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, comment)
+}
+
+// DeleteComment removes a comment
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+	commentExtID := c.Param("comment_ext_id")
+
+	if err := h.commentService.DeleteComment(userExtID.(string), taskExtID, commentExtID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "comment deleted successfully"})
+}
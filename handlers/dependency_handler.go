@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type DependencyHandler struct {
+	dependencyService *services.DependencyService
+}
+
+func NewDependencyHandler(dependencyService *services.DependencyService) *DependencyHandler {
+	return &DependencyHandler{dependencyService: dependencyService}
+}
+
+// GetDependencies lists what blocks a task and what it blocks
+func (h *DependencyHandler) GetDependencies(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	dependencies, err := h.dependencyService.GetDependencies(userExtID.(string), taskExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, dependencies)
+}
+
+// AddDependency marks another task as blocking this one
+func (h *DependencyHandler) AddDependency(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	var req models.AddDependencyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	dependencies, err := h.dependencyService.AddDependency(userExtID.(string), taskExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 201, dependencies)
+}
+
+// RemoveDependency unlinks a blocker from this task
+func (h *DependencyHandler) RemoveDependency(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+	blockerExtID := c.Param("blocker_ext_id")
+
+	if err := h.dependencyService.RemoveDependency(userExtID.(string), taskExtID, blockerExtID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "dependency removed successfully"})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+func init() {
+	// Report validation failures with JSON field names instead of Go struct field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// respondError maps a service error to its HTTP status and structured error body
+func respondError(c *gin.Context, err error) {
+	var svcErr *services.Error
+	if !errors.As(err, &svcErr) {
+		log.Printf("Internal error on %s %s: %v", c.Request.Method, c.FullPath(), err)
+		utils.ErrorResponse(c, 500, "internal server error")
+		return
+	}
+
+	switch {
+	case errors.Is(svcErr, services.ErrNotFound):
+		utils.ErrorResponseWithDetails(c, 404, "not_found", svcErr.Message, svcErr.Fields)
+	case errors.Is(svcErr, services.ErrForbidden):
+		utils.ErrorResponseWithDetails(c, 403, "forbidden", svcErr.Message, svcErr.Fields)
+	case errors.Is(svcErr, services.ErrConflict):
+		utils.ErrorResponseWithDetails(c, 409, "conflict", svcErr.Message, svcErr.Fields)
+	case errors.Is(svcErr, services.ErrValidation):
+		utils.ErrorResponseWithDetails(c, 422, "validation_error", svcErr.Message, svcErr.Fields)
+	case errors.Is(svcErr, services.ErrUnauthorized):
+		utils.ErrorResponseWithDetails(c, 401, "unauthorized", svcErr.Message, svcErr.Fields)
+	default:
+		utils.ErrorResponse(c, 500, "internal server error")
+	}
+}
+
+// bindJSON decodes the request body, answering 400 for malformed JSON and 422 for failed validation rules
+func bindJSON(c *gin.Context, req interface{}) bool {
+	err := c.ShouldBindJSON(req)
+	if err == nil {
+		return true
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make(map[string]string, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields[fe.Field()] = validationMessage(fe)
+		}
+		utils.ErrorResponseWithDetails(c, 422, "validation_error", "request validation failed", fields)
+		return false
+	}
+
+	utils.ErrorResponse(c, 400, "Invalid request body")
+	return false
+}
+
+// validationMessage turns a validator rule failure into a short readable message
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return "must be at least " + fe.Param() + " characters"
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	default:
+		return "failed the " + fe.Tag() + " rule"
+	}
+}
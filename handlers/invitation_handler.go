@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+}
+
+func NewInvitationHandler(invitationService *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService}
+}
+
+// CreateInvitation invites someone to a workspace by email
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	var req models.InviteMemberRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(userExtID.(string), workspaceExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 201, invitation)
+}
+
+// GetInvitations lists a workspace's invitations
+func (h *InvitationHandler) GetInvitations(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	invitations, err := h.invitationService.GetInvitations(userExtID.(string), workspaceExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, invitations)
+}
+
+// ResendInvitation sends a fresh link for a pending invitation
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+	invitationExtID := c.Param("invitation_ext_id")
+
+	invitation, err := h.invitationService.ResendInvitation(userExtID.(string), workspaceExtID, invitationExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, invitation)
+}
+
+// RevokeInvitation cancels a pending invitation
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+	invitationExtID := c.Param("invitation_ext_id")
+
+	if err := h.invitationService.RevokeInvitation(userExtID.(string), workspaceExtID, invitationExtID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "invitation revoked successfully"})
+}
+
+// PreviewInvitation shows what an invitation link is for, without authentication
+func (h *InvitationHandler) PreviewInvitation(c *gin.Context) {
+	token := c.Param("token")
+
+	preview, err := h.invitationService.PreviewInvitation(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, preview)
+}
+
+// AcceptInvitation joins the authenticated user to the inviting workspace
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+
+	var req models.AcceptInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	workspace, err := h.invitationService.AcceptInvitation(userExtID.(string), req.Token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, workspace)
+}
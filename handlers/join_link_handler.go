@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type JoinLinkHandler struct {
+	joinLinkService *services.JoinLinkService
+}
+
+func NewJoinLinkHandler(joinLinkService *services.JoinLinkService) *JoinLinkHandler {
+	return &JoinLinkHandler{joinLinkService: joinLinkService}
+}
+
+// CreateJoinLink creates a shareable join link for a workspace
+func (h *JoinLinkHandler) CreateJoinLink(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	var req models.CreateJoinLinkRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	link, err := h.joinLinkService.CreateJoinLink(userExtID.(string), workspaceExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 201, link)
+}
+
+// GetJoinLinks lists a workspace's join links
+func (h *JoinLinkHandler) GetJoinLinks(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	links, err := h.joinLinkService.GetJoinLinks(userExtID.(string), workspaceExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, links)
+}
+
+// GetJoinLinkUses lists who joined through a link
+func (h *JoinLinkHandler) GetJoinLinkUses(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+	linkExtID := c.Param("link_ext_id")
+
+	uses, err := h.joinLinkService.GetJoinLinkUses(userExtID.(string), workspaceExtID, linkExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, uses)
+}
+
+// RevokeJoinLink disables a join link
+func (h *JoinLinkHandler) RevokeJoinLink(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+	linkExtID := c.Param("link_ext_id")
+
+	link, err := h.joinLinkService.RevokeJoinLink(userExtID.(string), workspaceExtID, linkExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, link)
+}
+
+// PreviewJoinLink shows which workspace a join link leads to
+func (h *JoinLinkHandler) PreviewJoinLink(c *gin.Context) {
+	code := c.Param("code")
+
+	preview, err := h.joinLinkService.PreviewJoinLink(code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, preview)
+}
+
+// JoinWorkspace joins the authenticated user through a join link
+func (h *JoinLinkHandler) JoinWorkspace(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	code := c.Param("code")
+
+	workspace, err := h.joinLinkService.JoinWorkspace(userExtID.(string), code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, workspace)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type LabelHandler struct {
+	labelService *services.LabelService
+}
+
+func NewLabelHandler(labelService *services.LabelService) *LabelHandler {
+	return &LabelHandler{labelService: labelService}
+}
+
+// GetLabels lists a workspace's labels
+func (h *LabelHandler) GetLabels(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	labels, err := h.labelService.GetLabels(userExtID.(string), workspaceExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, labels)
+}
+
+// CreateLabel adds a label to a workspace
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	var req models.LabelRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	label, err := h.labelService.CreateLabel(userExtID.(string), workspaceExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 201, label)
+}
+
+// UpdateLabel renames or recolors a label
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+	labelExtID := c.Param("label_ext_id")
+
+	var req models.LabelRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	label, err := h.labelService.UpdateLabel(userExtID.(string), workspaceExtID, labelExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, label)
+}
+
+// DeleteLabel removes a label from the workspace
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+	labelExtID := c.Param("label_ext_id")
+
+	if err := h.labelService.DeleteLabel(userExtID.(string), workspaceExtID, labelExtID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "label deleted successfully"})
+}
+
+// AddTaskLabel attaches a label to a task
+func (h *LabelHandler) AddTaskLabel(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	var req models.TaskLabelRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	task, err := h.labelService.AddTaskLabel(userExtID.(string), taskExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, task)
+}
+
+// RemoveTaskLabel detaches a label from a task
+func (h *LabelHandler) RemoveTaskLabel(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+	labelExtID := c.Param("label_ext_id")
+
+	task, err := h.labelService.RemoveTaskLabel(userExtID.(string), taskExtID, labelExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, task)
+}
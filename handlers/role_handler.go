@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+func NewRoleHandler(roleService *services.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// GetRoles lists a workspace's roles
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	roles, err := h.roleService.GetRoles(userExtID.(string), workspaceExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, roles)
+}
+
+// CreateRole adds a custom role to a workspace
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	var req models.WorkspaceRoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	role, err := h.roleService.CreateRole(userExtID.(string), workspaceExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 201, role)
+}
+
+// UpdateRole changes a role's name, description and permissions
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+	roleExtID := c.Param("role_ext_id")
+
+	var req models.WorkspaceRoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(userExtID.(string), workspaceExtID, roleExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, role)
+}
+
+// DeleteRole removes an unused custom role
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+	roleExtID := c.Param("role_ext_id")
+
+	if err := h.roleService.DeleteRole(userExtID.(string), workspaceExtID, roleExtID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "role deleted successfully"})
+}
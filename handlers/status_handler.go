@@ -1,8 +1,6 @@
 package handlers
 
 import (
-	"strings"
-
 	"github.com/gin-gonic/gin"
 	"github.com/grahagandangr/nexboard-be/models"
 	"github.com/grahagandangr/nexboard-be/services"
@@ -17,28 +15,66 @@ func NewStatusHandler(statusService *services.StatusService) *StatusHandler {
 	return &StatusHandler{statusService: statusService}
 }
 
-// CreateStatus handles new status
-func (h *StatusHandler) CreateStatus(c *gin.Context) {
+// CreateWorkspaceStatus handles a new status shared by every board of a workspace
+func (h *StatusHandler) CreateWorkspaceStatus(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	var req models.StatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	status, err := h.statusService.CreateWorkspaceStatus(userExtID.(string), workspaceExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 201, status)
+}
+
+// GetWorkspaceStatuses returns the workspace-wide statuses
+func (h *StatusHandler) GetWorkspaceStatuses(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	statuses, err := h.statusService.GetWorkspaceStatuses(userExtID.(string), workspaceExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, statuses)
+}
+
+// CreateBoardStatus handles a new status only available on one board
+func (h *StatusHandler) CreateBoardStatus(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	boardExtID := c.Param("external_id")
+
 	var req models.StatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	status, err := h.statusService.CreateStatus(&req)
+	status, err := h.statusService.CreateBoardStatus(userExtID.(string), boardExtID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, 201, status)
 }
 
-// GetAllStatuses returns a list of all statuses
-func (h *StatusHandler) GetAllStatuses(c *gin.Context) {
-	statuses, err := h.statusService.GetAllStatuses()
+// GetBoardStatuses returns every status usable on a board
+func (h *StatusHandler) GetBoardStatuses(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	boardExtID := c.Param("external_id")
+
+	statuses, err := h.statusService.GetBoardStatuses(userExtID.(string), boardExtID)
 	if err != nil {
-		utils.ErrorResponse(c, 500, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -47,11 +83,12 @@ func (h *StatusHandler) GetAllStatuses(c *gin.Context) {
 
 // GetStatus returns a single status detail
 func (h *StatusHandler) GetStatus(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
 	extID := c.Param("external_id")
 
-	status, err := h.statusService.GetStatus(extID)
+	status, err := h.statusService.GetStatus(userExtID.(string), extID)
 	if err != nil {
-		utils.ErrorResponse(c, 404, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -60,37 +97,85 @@ func (h *StatusHandler) GetStatus(c *gin.Context) {
 
 // UpdateStatus changes an existing status
 func (h *StatusHandler) UpdateStatus(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
 	extID := c.Param("external_id")
 
 	var req models.StatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	status, err := h.statusService.UpdateStatus(extID, &req)
+	status, err := h.statusService.UpdateStatus(userExtID.(string), extID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, 200, status)
 }
 
-// DeleteStatus removes status safely
+// DeleteStatus removes status safely, moving its tasks to ?migrate_to=<status_external_id> when given
 func (h *StatusHandler) DeleteStatus(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
 	extID := c.Param("external_id")
 
-	err := h.statusService.DeleteStatus(extID)
+	migrated, err := h.statusService.DeleteStatus(userExtID.(string), extID, c.Query("migrate_to"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, gin.H{"message": "status deleted successfully", "migrated_tasks": migrated})
+}
+
+// GetStatusAuditLogs returns the status change history of a workspace
+func (h *StatusHandler) GetStatusAuditLogs(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	logs, err := h.statusService.GetStatusAuditLogs(userExtID.(string), workspaceExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, logs)
+}
+
+// ReorderWorkspaceStatuses sets the column order of the workspace-wide statuses
+func (h *StatusHandler) ReorderWorkspaceStatuses(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	var req models.ReorderStatusesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	statuses, err := h.statusService.ReorderWorkspaceStatuses(userExtID.(string), workspaceExtID, &req)
 	if err != nil {
-		// Translate conflict to HTTP 409
-		if strings.HasPrefix(err.Error(), "conflict") {
-			utils.ErrorResponse(c, 409, err.Error())
-			return
-		}
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
-	utils.SuccessResponse(c, 200, gin.H{"message": "status deleted successfully"})
+	utils.SuccessResponse(c, 200, statuses)
+}
+
+// ReorderBoardStatuses sets the column order of a board's own statuses
+func (h *StatusHandler) ReorderBoardStatuses(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	boardExtID := c.Param("external_id")
+
+	var req models.ReorderStatusesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	statuses, err := h.statusService.ReorderBoardStatuses(userExtID.(string), boardExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, statuses)
 }
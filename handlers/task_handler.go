@@ -21,48 +21,89 @@ func (h *TaskHandler) CreateBoardTask(c *gin.Context) {
 	boardExtID := c.Param("external_id")
 
 	var req models.TaskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	task, err := h.taskService.CreateTask(userExtID.(string), boardExtID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, 201, task)
 }
 
-// GetBoardTasks fetches all tasks linked to a board
+// GetBoardTasks fetches the tasks linked to a board, filtered by ?state=open|completed
+// and ?labels=a,b&labels_match=any|all
 func (h *TaskHandler) GetBoardTasks(c *gin.Context) {
 	userExtID, _ := c.Get("user_external_id")
 	boardExtID := c.Param("external_id")
 
-	tasks, err := h.taskService.GetBoardTasks(userExtID.(string), boardExtID)
+	tasks, err := h.taskService.GetBoardTasks(userExtID.(string), boardExtID, c.Query("state"), c.QueryArray("labels"), c.Query("labels_match"))
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, 200, tasks)
 }
 
+// GetTask returns a single task with its joined details
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	task, err := h.taskService.GetTask(userExtID.(string), taskExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, task)
+}
+
+// GetChildTasks lists a task's direct subtasks
+func (h *TaskHandler) GetChildTasks(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	children, err := h.taskService.GetChildTasks(userExtID.(string), taskExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, children)
+}
+
+// GetEpic shows a task with its subtasks rolled up by status category
+func (h *TaskHandler) GetEpic(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	epic, err := h.taskService.GetEpic(userExtID.(string), taskExtID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, epic)
+}
+
 // UpdateTask modifies a task via PUT
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	userExtID, _ := c.Get("user_external_id")
 	taskExtID := c.Param("external_id")
 
 	var req models.TaskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	task, err := h.taskService.UpdateTask(userExtID.(string), taskExtID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -76,7 +117,7 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 
 	err := h.taskService.DeleteTask(userExtID.(string), taskExtID)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -89,14 +130,32 @@ func (h *TaskHandler) MoveTask(c *gin.Context) {
 	taskExtID := c.Param("external_id")
 
 	var req models.MoveTaskStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	task, err := h.taskService.MoveTaskStatus(userExtID.(string), taskExtID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, task)
+}
+
+// MoveTaskPosition handles drag-and-drop moves within or across status columns
+func (h *TaskHandler) MoveTaskPosition(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	taskExtID := c.Param("external_id")
+
+	var req models.MoveTaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	task, err := h.taskService.MoveTask(userExtID.(string), taskExtID, &req)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -109,14 +168,13 @@ func (h *TaskHandler) AssignTask(c *gin.Context) {
 	taskExtID := c.Param("external_id")
 
 	var req models.AssignTaskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	task, err := h.taskService.AssignTask(userExtID.(string), taskExtID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/storage"
+	"github.com/grahagandangr/nexboard-be/utils"
+	_ "github.com/lib/pq"
+)
+
+// openTestDB connects to TEST_DATABASE_URL inside a fresh schema with every migration applied,
+// and drops the schema when the test ends. Tests are skipped when the variable is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Errorf("drop test schema: %v", err)
+		}
+	})
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+
+	db, err := sql.Open("postgres", u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	files, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up, _, _ := strings.Cut(string(content), "-- +migrate Down")
+		if _, err := db.Exec(strings.TrimPrefix(up, "-- +migrate Up")); err != nil {
+			t.Fatalf("apply %s: %v", filepath.Base(f), err)
+		}
+	}
+
+	return db
+}
+
+// taskFixture is a workspace with one board and one task, plus a user outside the workspace
+type taskFixture struct {
+	router   *gin.Engine
+	owner    *models.User
+	outsider *models.User
+	task     *models.TaskResponse
+	statuses []*models.Status
+}
+
+func newTaskFixture(t *testing.T) *taskFixture {
+	t.Helper()
+	db := openTestDB(t)
+
+	userRepo := repositories.NewUserRepository(db)
+	workspaceRepo := repositories.NewWorkspaceRepository(db)
+	roleRepo := repositories.NewWorkspaceRoleRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	statusRepo := repositories.NewStatusRepository(db)
+	taskRepo := repositories.NewTaskRepository(db)
+	dependencyRepo := repositories.NewTaskDependencyRepository(db)
+	labelRepo := repositories.NewLabelRepository(db)
+
+	blobs, err := storage.NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workspaceService := services.NewWorkspaceService(workspaceRepo, userRepo, roleRepo, blobs)
+	boardService := services.NewBoardService(boardRepo, workspaceRepo, userRepo, blobs)
+	taskService := services.NewTaskService(taskRepo, boardRepo, statusRepo, userRepo, workspaceRepo, dependencyRepo, labelRepo, blobs, models.SubtaskDeleteOrphan, models.BlockedCompletionWarn)
+
+	f := &taskFixture{}
+	f.owner = createTestUser(t, userRepo, "owner@example.com")
+	f.outsider = createTestUser(t, userRepo, "outsider@example.com")
+
+	ws, err := workspaceService.CreateWorkspace(f.owner.ExternalID, &models.WorkspaceRequest{Name: "Team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	board, err := boardService.CreateBoard(f.owner.ExternalID, ws.ExternalID, &models.BoardRequest{Name: "Roadmap"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := workspaceRepo.GetWorkspaceByExternalID(ws.ExternalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.statuses, err = statusRepo.GetStatusesByWorkspaceID(w.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.statuses) < 2 {
+		t.Fatalf("workspace was seeded with %d statuses, want at least 2", len(f.statuses))
+	}
+
+	task, err := taskService.CreateTask(f.owner.ExternalID, board.ExternalID, &models.TaskRequest{
+		Title:            "Write the launch plan",
+		StatusExternalID: f.statuses[0].ExternalID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.task, err = taskService.GetTask(f.owner.ExternalID, task.ExternalID); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	f.router = gin.New()
+	// Stands in for AuthRequired, which would take the caller from a JWT
+	f.router.Use(func(c *gin.Context) {
+		c.Set("user_external_id", c.GetHeader("X-Test-User"))
+	})
+	taskHandler := NewTaskHandler(taskService)
+	tasks := f.router.Group("/api/tasks")
+	tasks.GET("/:external_id", taskHandler.GetTask)
+	tasks.PUT("/:external_id", taskHandler.UpdateTask)
+	tasks.DELETE("/:external_id", taskHandler.DeleteTask)
+	tasks.PATCH("/:external_id/status", taskHandler.MoveTask)
+	tasks.PATCH("/:external_id/move", taskHandler.MoveTaskPosition)
+	tasks.PATCH("/:external_id/assign", taskHandler.AssignTask)
+
+	return f
+}
+
+func createTestUser(t *testing.T, userRepo *repositories.UserRepository, email string) *models.User {
+	t.Helper()
+	user := &models.User{
+		ExternalID: utils.GenerateUUID(),
+		Name:       strings.Split(email, "@")[0],
+		Email:      email,
+		Password:   "not-a-real-hash",
+	}
+	if err := userRepo.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func (f *taskFixture) do(t *testing.T, user *models.User, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", user.ExternalID)
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTaskMutationsRequireWorkspaceMembership(t *testing.T) {
+	f := newTaskFixture(t)
+	taskPath := "/api/tasks/" + f.task.ExternalID
+	otherStatus := f.statuses[1].ExternalID
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+	}{
+		{"view", http.MethodGet, taskPath, nil},
+		{"update", http.MethodPut, taskPath, map[string]any{"title": "Hijacked", "status_external_id": otherStatus}},
+		{"move status", http.MethodPatch, taskPath + "/status", map[string]any{"status_external_id": otherStatus}},
+		{"move position", http.MethodPatch, taskPath + "/move", map[string]any{"status_external_id": otherStatus}},
+		{"assign to self", http.MethodPatch, taskPath + "/assign", map[string]any{"assigned_to_external_id": f.outsider.ExternalID}},
+		{"unassign", http.MethodPatch, taskPath + "/assign", map[string]any{"assigned_to_external_id": nil}},
+		{"delete", http.MethodDelete, taskPath, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := f.do(t, f.outsider, tt.method, tt.path, tt.body)
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("%s %s by a non-member = %d, want %d: %s", tt.method, tt.path, rec.Code, http.StatusForbidden, rec.Body.String())
+			}
+		})
+	}
+
+	// None of the refused requests may have changed the task
+	rec := f.do(t, f.owner, http.MethodGet, taskPath, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s by the owner = %d, want %d: %s", taskPath, rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var got models.TaskResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != f.task.Title || got.Status.ExternalID != f.task.Status.ExternalID || got.AssignedTo != nil || got.Rank != f.task.Rank {
+		t.Errorf("task changed after refused requests: got %+v, want %+v", got, f.task)
+	}
+}
+
+func TestTaskMutationsOnUnknownTask(t *testing.T) {
+	f := newTaskFixture(t)
+	taskPath := "/api/tasks/" + utils.GenerateUUID()
+	status := f.statuses[0].ExternalID
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+	}{
+		{"update", http.MethodPut, taskPath, map[string]any{"title": "Ghost", "status_external_id": status}},
+		{"move status", http.MethodPatch, taskPath + "/status", map[string]any{"status_external_id": status}},
+		{"move position", http.MethodPatch, taskPath + "/move", map[string]any{"status_external_id": status}},
+		{"assign", http.MethodPatch, taskPath + "/assign", map[string]any{"assigned_to_external_id": f.outsider.ExternalID}},
+		{"delete", http.MethodDelete, taskPath, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := f.do(t, f.outsider, tt.method, tt.path, tt.body)
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("%s %s = %d, want %d: %s", tt.method, tt.path, rec.Code, http.StatusNotFound, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskAssigneeMustBeWorkspaceMember(t *testing.T) {
+	f := newTaskFixture(t)
+	taskPath := "/api/tasks/" + f.task.ExternalID
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+	}{
+		{"assign", http.MethodPatch, taskPath + "/assign", map[string]any{"assigned_to_external_id": f.outsider.ExternalID}},
+		{"update", http.MethodPut, taskPath, map[string]any{
+			"title":                   f.task.Title,
+			"status_external_id":      f.task.Status.ExternalID,
+			"assigned_to_external_id": f.outsider.ExternalID,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := f.do(t, f.owner, tt.method, tt.path, tt.body)
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("assigning a non-member via %s = %d, want %d: %s", tt.name, rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+			}
+		})
+	}
+}
@@ -18,8 +18,7 @@ func NewWorkspaceHandler(workspaceService *services.WorkspaceService) *Workspace
 // CreateWorkspace handles creating a new workspace
 func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
 	var req models.WorkspaceRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -27,7 +26,7 @@ func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
 
 	workspace, err := h.workspaceService.CreateWorkspace(userExtID.(string), &req)
 	if err != nil {
-		utils.ErrorResponse(c, 500, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -40,7 +39,7 @@ func (h *WorkspaceHandler) GetUserWorkspaces(c *gin.Context) {
 
 	workspaces, err := h.workspaceService.GetUserWorkspaces(userExtID.(string))
 	if err != nil {
-		utils.ErrorResponse(c, 500, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -54,7 +53,7 @@ func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
 
 	workspace, err := h.workspaceService.GetWorkspace(userExtID.(string), workspaceExtID)
 	if err != nil {
-		utils.ErrorResponse(c, 404, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -67,15 +66,13 @@ func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
 	workspaceExtID := c.Param("external_id")
 
 	var req models.WorkspaceRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	workspace, err := h.workspaceService.UpdateWorkspace(userExtID.(string), workspaceExtID, &req)
 	if err != nil {
-		// Use 403 or 400 depending on exact error parsing. 400 is fine as catch all.
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -88,7 +85,7 @@ func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
 	workspaceExtID := c.Param("external_id")
 
 	if err := h.workspaceService.DeleteWorkspace(userExtID.(string), workspaceExtID); err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -102,62 +99,74 @@ func (h *WorkspaceHandler) GetMembers(c *gin.Context) {
 
 	members, err := h.workspaceService.GetMembers(userExtID.(string), workspaceExtID)
 	if err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, 200, members)
 }
 
-// InviteMember adds a new member to a workspace
-func (h *WorkspaceHandler) InviteMember(c *gin.Context) {
+// UpdateMemberRole changes a member's role
+func (h *WorkspaceHandler) UpdateMemberRole(c *gin.Context) {
 	userExtID, _ := c.Get("user_external_id")
 	workspaceExtID := c.Param("external_id")
+	targetUserExtID := c.Param("user_ext_id")
 
-	var req models.InviteMemberRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	var req models.UpdateMemberRoleRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if err := h.workspaceService.InviteMember(userExtID.(string), workspaceExtID, &req); err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+	if err := h.workspaceService.UpdateMemberRole(userExtID.(string), workspaceExtID, targetUserExtID, &req); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	utils.SuccessResponse(c, 201, gin.H{"message": "member added successfully"})
+	utils.SuccessResponse(c, 200, gin.H{"message": "member role updated successfully"})
 }
 
-// UpdateMemberRole changes a member's role
-func (h *WorkspaceHandler) UpdateMemberRole(c *gin.Context) {
+// RemoveMember removes a member from a workspace
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
 	userExtID, _ := c.Get("user_external_id")
 	workspaceExtID := c.Param("external_id")
 	targetUserExtID := c.Param("user_ext_id")
 
-	var req models.UpdateMemberRoleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, 400, "Invalid request body")
+	if err := h.workspaceService.RemoveMember(userExtID.(string), workspaceExtID, targetUserExtID); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	if err := h.workspaceService.UpdateMemberRole(userExtID.(string), workspaceExtID, targetUserExtID, &req); err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+	utils.SuccessResponse(c, 200, gin.H{"message": "member removed successfully"})
+}
+
+// LeaveWorkspace removes the current user from a workspace
+func (h *WorkspaceHandler) LeaveWorkspace(c *gin.Context) {
+	userExtID, _ := c.Get("user_external_id")
+	workspaceExtID := c.Param("external_id")
+
+	if err := h.workspaceService.LeaveWorkspace(userExtID.(string), workspaceExtID); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	utils.SuccessResponse(c, 200, gin.H{"message": "member role updated successfully"})
+	utils.SuccessResponse(c, 200, gin.H{"message": "left workspace successfully"})
 }
 
-// RemoveMember removes a member from a workspace
-func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+// TransferOwnership hands the workspace to another member
+func (h *WorkspaceHandler) TransferOwnership(c *gin.Context) {
 	userExtID, _ := c.Get("user_external_id")
 	workspaceExtID := c.Param("external_id")
-	targetUserExtID := c.Param("user_ext_id")
 
-	if err := h.workspaceService.RemoveMember(userExtID.(string), workspaceExtID, targetUserExtID); err != nil {
-		utils.ErrorResponse(c, 400, err.Error())
+	var req models.TransferOwnershipRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	utils.SuccessResponse(c, 200, gin.H{"message": "member removed successfully"})
+	workspace, err := h.workspaceService.TransferOwnership(userExtID.(string), workspaceExtID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, workspace)
 }
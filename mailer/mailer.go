@@ -0,0 +1,13 @@
+package mailer
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers outgoing email; implementations are picked by the MAIL_DRIVER setting
+type Mailer interface {
+	Send(msg *Message) error
+}
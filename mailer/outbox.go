@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// OutboxMailer writes messages to a directory (one file per message) or to stdout,
+// for local development and tests where no SMTP relay is available
+type OutboxMailer struct {
+	dir  string
+	from string
+	out  io.Writer
+	mu   sync.Mutex
+}
+
+// NewOutboxMailer writes into dir, or to stdout when dir is empty
+func NewOutboxMailer(dir, from string) *OutboxMailer {
+	return &OutboxMailer{dir: dir, from: from, out: os.Stdout}
+}
+
+// Send stores the rendered message in the outbox
+func (m *OutboxMailer) Send(msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	raw := buildMessage(m.from, msg)
+
+	if m.dir == "" {
+		_, err := fmt.Fprintf(m.out, "----- outgoing mail -----\n%s\n-------------------------\n", raw)
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d.eml", time.Now().UnixNano())
+	return os.WriteFile(filepath.Join(m.dir, name), raw, 0o644)
+}
@@ -9,9 +9,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/grahagandangr/nexboard-be/config"
 	"github.com/grahagandangr/nexboard-be/handlers"
+	"github.com/grahagandangr/nexboard-be/mailer"
 	"github.com/grahagandangr/nexboard-be/middleware"
+	"github.com/grahagandangr/nexboard-be/policy"
 	"github.com/grahagandangr/nexboard-be/repositories"
 	"github.com/grahagandangr/nexboard-be/services"
+	"github.com/grahagandangr/nexboard-be/storage"
 )
 
 func main() {
@@ -26,34 +29,80 @@ func main() {
 
 	// 3. Initialize repositories
 	userRepo := repositories.NewUserRepository(config.DB)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(config.DB)
+	userTokenRepo := repositories.NewUserTokenRepository(config.DB)
 	workspaceRepo := repositories.NewWorkspaceRepository(config.DB)
 	boardRepo := repositories.NewBoardRepository(config.DB)
 	statusRepo := repositories.NewStatusRepository(config.DB)
 	taskRepo := repositories.NewTaskRepository(config.DB)
+	invitationRepo := repositories.NewWorkspaceInvitationRepository(config.DB)
+	joinLinkRepo := repositories.NewWorkspaceJoinLinkRepository(config.DB)
+	roleRepo := repositories.NewWorkspaceRoleRepository(config.DB)
+	commentRepo := repositories.NewTaskCommentRepository(config.DB)
+	attachmentRepo := repositories.NewAttachmentRepository(config.DB)
+	checklistRepo := repositories.NewChecklistRepository(config.DB)
+	dependencyRepo := repositories.NewTaskDependencyRepository(config.DB)
+	labelRepo := repositories.NewLabelRepository(config.DB)
 
-	// 4. Initialize services
-	authService := services.NewAuthService(userRepo)
-	workspaceService := services.NewWorkspaceService(workspaceRepo, userRepo)
-	boardService := services.NewBoardService(boardRepo, workspaceRepo, userRepo)
-	statusService := services.NewStatusService(statusRepo)
-	taskService := services.NewTaskService(taskRepo, boardRepo, statusRepo, userRepo, workspaceRepo)
+	// 4. Initialize mail delivery
+	var mail mailer.Mailer
+	if config.AppConfig.MailDriver == "smtp" {
+		mail = mailer.NewSMTPMailer(
+			config.AppConfig.SMTPHost,
+			config.AppConfig.SMTPPort,
+			config.AppConfig.SMTPUsername,
+			config.AppConfig.SMTPPassword,
+			config.AppConfig.MailFrom,
+		)
+	} else {
+		mail = mailer.NewOutboxMailer(config.AppConfig.MailOutboxDir, config.AppConfig.MailFrom)
+	}
+
+	// 5. Initialize attachment storage
+	blobs, err := storage.NewLocalBlobStore(config.AppConfig.StorageLocalDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment storage: %v", err)
+	}
 
-	// 5. Initialize handlers
+	// 6. Initialize services
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, userTokenRepo, invitationRepo, mail)
+	workspaceService := services.NewWorkspaceService(workspaceRepo, userRepo, roleRepo, blobs)
+	boardService := services.NewBoardService(boardRepo, workspaceRepo, userRepo, blobs)
+	statusService := services.NewStatusService(statusRepo, boardRepo, workspaceRepo, userRepo)
+	taskService := services.NewTaskService(taskRepo, boardRepo, statusRepo, userRepo, workspaceRepo, dependencyRepo, labelRepo, blobs, config.AppConfig.SubtaskDeletePolicy, config.AppConfig.BlockedCompletionPolicy)
+	invitationService := services.NewInvitationService(invitationRepo, workspaceRepo, userRepo, roleRepo, mail)
+	joinLinkService := services.NewJoinLinkService(joinLinkRepo, workspaceRepo, userRepo, roleRepo)
+	roleService := services.NewRoleService(roleRepo, workspaceRepo, userRepo)
+	commentService := services.NewCommentService(commentRepo, taskRepo, boardRepo, workspaceRepo, userRepo)
+	attachmentService := services.NewAttachmentService(attachmentRepo, taskRepo, boardRepo, workspaceRepo, userRepo, blobs, config.AppConfig.AttachmentMaxSize)
+	checklistService := services.NewChecklistService(checklistRepo, taskRepo, boardRepo, workspaceRepo, userRepo)
+	dependencyService := services.NewDependencyService(dependencyRepo, taskRepo, boardRepo, workspaceRepo, userRepo)
+	labelService := services.NewLabelService(labelRepo, taskRepo, boardRepo, workspaceRepo, userRepo)
+
+	// 7. Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	workspaceHandler := handlers.NewWorkspaceHandler(workspaceService)
 	boardHandler := handlers.NewBoardHandler(boardService)
 	statusHandler := handlers.NewStatusHandler(statusService)
 	taskHandler := handlers.NewTaskHandler(taskService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	joinLinkHandler := handlers.NewJoinLinkHandler(joinLinkService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	checklistHandler := handlers.NewChecklistHandler(checklistService)
+	dependencyHandler := handlers.NewDependencyHandler(dependencyService)
+	labelHandler := handlers.NewLabelHandler(labelService)
 
-	// 6. Setup Gin router
+	// 8. Setup Gin router
 	router := gin.Default()
 
-	// 7. Health check endpoint
+	// 9. Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok", "message": "NexBoard API is running smoothly."})
 	})
 
-	// 8. API routes setup
+	// 10. API routes setup
 	api := router.Group("/api")
 	{
 		// ---- PUBLIC ROUTES ----
@@ -61,15 +110,37 @@ func main() {
 		{
 			users.POST("/login", authHandler.Login)
 			users.POST("/register", authHandler.Register)
+			users.POST("/token/refresh", authHandler.RefreshToken)
+			users.POST("/logout", authHandler.Logout)
+			users.POST("/password/forgot", authHandler.ForgotPassword)
+			users.POST("/password/reset", authHandler.ResetPassword)
+			users.GET("/verify", authHandler.VerifyEmail)
+			users.POST("/verify", authHandler.VerifyEmail)
+			users.POST("/verify/resend", authHandler.ResendVerification)
+			users.POST("/email/confirm", authHandler.ConfirmEmailChange)
+		}
+
+		// ---- INVITATION LINKS ----
+		// Accepting is open to unverified accounts: the invitation itself proves the address
+		invitations := api.Group("/invitations")
+		{
+			invitations.GET("/:token", invitationHandler.PreviewInvitation)
+			invitations.POST("/accept", middleware.AuthRequired(), invitationHandler.AcceptInvitation)
 		}
 
 		// ---- PROTECTED ROUTES ----
 		protected := api.Group("")
-		protected.Use(middleware.AuthRequired())
+		protected.Use(middleware.AuthRequired(), middleware.ReadOnlyUntilVerified())
 		{
 			// User Profile
 			protected.GET("/users/profile", authHandler.GetProfile)
 			protected.PUT("/users/profile", authHandler.UpdateProfile)
+			protected.PUT("/users/profile/password", authHandler.ChangePassword)
+			protected.POST("/users/profile/email", authHandler.RequestEmailChange)
+
+			// Join Links
+			protected.GET("/join/:code", joinLinkHandler.PreviewJoinLink)
+			protected.POST("/join/:code", joinLinkHandler.JoinWorkspace)
 
 			// Workspaces
 			workspaces := protected.Group("/workspaces")
@@ -79,22 +150,71 @@ func main() {
 				workspaces.GET("/:external_id", workspaceHandler.GetWorkspace)
 				workspaces.PUT("/:external_id", workspaceHandler.UpdateWorkspace)
 				workspaces.DELETE("/:external_id", workspaceHandler.DeleteWorkspace)
+				workspaces.POST("/:external_id/transfer-ownership", workspaceHandler.TransferOwnership)
 
 				// Workspace Members
 				members := workspaces.Group("/:external_id/members")
 				{
 					members.GET("", workspaceHandler.GetMembers)
-					members.POST("", workspaceHandler.InviteMember)
+					members.POST("", invitationHandler.CreateInvitation) // Kept for existing clients, creates an invitation
 					members.PUT("/:user_ext_id", workspaceHandler.UpdateMemberRole)
+					members.DELETE("/me", workspaceHandler.LeaveWorkspace)
 					members.DELETE("/:user_ext_id", workspaceHandler.RemoveMember)
 				}
 
+				// Workspace Roles
+				roles := workspaces.Group("/:external_id/roles")
+				{
+					roles.GET("", roleHandler.GetRoles)
+					roles.POST("", roleHandler.CreateRole)
+					roles.PUT("/:role_ext_id", roleHandler.UpdateRole)
+					roles.DELETE("/:role_ext_id", roleHandler.DeleteRole)
+				}
+
+				// Workspace Invitations
+				invitations := workspaces.Group("/:external_id/invitations")
+				invitations.Use(middleware.RequireWorkspacePermission(workspaceRepo, policy.ActionManage, policy.ResourceInvitation))
+				{
+					invitations.GET("", invitationHandler.GetInvitations)
+					invitations.POST("", invitationHandler.CreateInvitation)
+					invitations.POST("/:invitation_ext_id/resend", invitationHandler.ResendInvitation)
+					invitations.DELETE("/:invitation_ext_id", invitationHandler.RevokeInvitation)
+				}
+
+				// Workspace Join Links
+				joinLinks := workspaces.Group("/:external_id/join-links")
+				joinLinks.Use(middleware.RequireWorkspacePermission(workspaceRepo, policy.ActionManage, policy.ResourceJoinLink))
+				{
+					joinLinks.POST("", joinLinkHandler.CreateJoinLink)
+					joinLinks.GET("", joinLinkHandler.GetJoinLinks)
+					joinLinks.GET("/:link_ext_id/uses", joinLinkHandler.GetJoinLinkUses)
+					joinLinks.DELETE("/:link_ext_id", joinLinkHandler.RevokeJoinLink)
+				}
+
 				// Workspace Boards
 				boards := workspaces.Group("/:external_id/boards")
 				{
 					boards.POST("", boardHandler.CreateWorkspaceBoard)
 					boards.GET("", boardHandler.GetWorkspaceBoards)
 				}
+
+				// Workspace Statuses
+				workspaceStatuses := workspaces.Group("/:external_id/statuses")
+				{
+					workspaceStatuses.POST("", statusHandler.CreateWorkspaceStatus)
+					workspaceStatuses.GET("", statusHandler.GetWorkspaceStatuses)
+					workspaceStatuses.PUT("/order", statusHandler.ReorderWorkspaceStatuses)
+					workspaceStatuses.GET("/audit", statusHandler.GetStatusAuditLogs)
+				}
+
+				// Workspace Labels
+				labels := workspaces.Group("/:external_id/labels")
+				{
+					labels.GET("", labelHandler.GetLabels)
+					labels.POST("", labelHandler.CreateLabel)
+					labels.PUT("/:label_ext_id", labelHandler.UpdateLabel)
+					labels.DELETE("/:label_ext_id", labelHandler.DeleteLabel)
+				}
 			}
 
 			// Boards (direct manipulation)
@@ -110,22 +230,75 @@ func main() {
 					tasks.POST("", taskHandler.CreateBoardTask)
 					tasks.GET("", taskHandler.GetBoardTasks)
 				}
+
+				// Board Statuses
+				boardStatuses := boards.Group("/:external_id/statuses")
+				{
+					boardStatuses.POST("", statusHandler.CreateBoardStatus)
+					boardStatuses.GET("", statusHandler.GetBoardStatuses)
+					boardStatuses.PUT("/order", statusHandler.ReorderBoardStatuses)
+				}
 			}
 
 			// Tasks (direct manipulation)
 			tasks := protected.Group("/tasks")
 			{
+				tasks.GET("/:external_id", taskHandler.GetTask)
 				tasks.PUT("/:external_id", taskHandler.UpdateTask)
 				tasks.DELETE("/:external_id", taskHandler.DeleteTask)
 				tasks.PATCH("/:external_id/status", taskHandler.MoveTask)
+				tasks.PATCH("/:external_id/move", taskHandler.MoveTaskPosition)
 				tasks.PATCH("/:external_id/assign", taskHandler.AssignTask)
+				tasks.GET("/:external_id/children", taskHandler.GetChildTasks)
+				tasks.GET("/:external_id/epic", taskHandler.GetEpic)
+
+				// Task Comments
+				comments := tasks.Group("/:external_id/comments")
+				{
+					comments.POST("", commentHandler.CreateComment)
+					comments.GET("", commentHandler.GetComments)
+					comments.PUT("/:comment_ext_id", commentHandler.UpdateComment)
+					comments.DELETE("/:comment_ext_id", commentHandler.DeleteComment)
+				}
+
+				// Task Attachments
+				attachments := tasks.Group("/:external_id/attachments")
+				{
+					attachments.POST("", attachmentHandler.UploadAttachment)
+					attachments.GET("", attachmentHandler.GetAttachments)
+					attachments.GET("/:attachment_ext_id/download", attachmentHandler.DownloadAttachment)
+					attachments.DELETE("/:attachment_ext_id", attachmentHandler.DeleteAttachment)
+				}
+
+				// Task Checklist
+				checklist := tasks.Group("/:external_id/checklist")
+				{
+					checklist.POST("", checklistHandler.CreateItem)
+					checklist.GET("", checklistHandler.GetItems)
+					checklist.PUT("/order", checklistHandler.ReorderItems)
+					checklist.PUT("/:item_ext_id", checklistHandler.UpdateItem)
+					checklist.DELETE("/:item_ext_id", checklistHandler.DeleteItem)
+				}
+
+				// Task Dependencies
+				dependencies := tasks.Group("/:external_id/dependencies")
+				{
+					dependencies.GET("", dependencyHandler.GetDependencies)
+					dependencies.POST("", dependencyHandler.AddDependency)
+					dependencies.DELETE("/:blocker_ext_id", dependencyHandler.RemoveDependency)
+				}
+
+				// Task Labels
+				taskLabels := tasks.Group("/:external_id/labels")
+				{
+					taskLabels.POST("", labelHandler.AddTaskLabel)
+					taskLabels.DELETE("/:label_ext_id", labelHandler.RemoveTaskLabel)
+				}
 			}
 
-			// Status Master Data
+			// Statuses (direct manipulation)
 			statuses := protected.Group("/statuses")
 			{
-				statuses.POST("", statusHandler.CreateStatus)
-				statuses.GET("", statusHandler.GetAllStatuses)
 				statuses.GET("/:external_id", statusHandler.GetStatus)
 				statuses.PUT("/:external_id", statusHandler.UpdateStatus)
 				statuses.DELETE("/:external_id", statusHandler.DeleteStatus)
@@ -133,7 +306,7 @@ func main() {
 		}
 	}
 
-	// 9. Setup graceful shutdown
+	// 11. Setup graceful shutdown
 	go func() {
 		sigint := make(chan os.Signal, 1)
 		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
@@ -144,7 +317,7 @@ func main() {
 		os.Exit(0)
 	}()
 
-	// 10. Start server
+	// 12. Start server
 	port := config.AppConfig.Port
 	if port == "" {
 		port = "8080"
@@ -39,6 +39,8 @@ func AuthRequired() gin.HandlerFunc {
 		// Set user info in context
 		c.Set("user_external_id", claims.ExternalID)
 		c.Set("user_email", claims.Email)
+		c.Set("session_id", claims.SessionID)
+		c.Set("email_verified", !claims.Unverified)
 
 		c.Next()
 	}
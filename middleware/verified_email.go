@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+// ReadOnlyUntilVerified only lets accounts with an unverified email perform read requests.
+// Must run after AuthRequired.
+func ReadOnlyUntilVerified() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if !c.GetBool("email_verified") {
+			utils.ErrorResponseWithDetails(c, 403, "email_not_verified", "verify your email address before making changes", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+// WorkspaceRoleResolver looks up a user's role and permissions in a workspace
+type WorkspaceRoleResolver interface {
+	GetMemberAccessByExternalIDs(workspaceExternalID, userExternalID string) (*models.MemberAccess, error)
+}
+
+// RequireWorkspacePermission rejects requests whose user may not perform the action in the
+// workspace named by the :external_id route parameter. The role is stored as "workspace_role".
+// Must run after AuthRequired.
+func RequireWorkspacePermission(roles WorkspaceRoleResolver, action policy.Action, resource policy.Resource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access, err := roles.GetMemberAccessByExternalIDs(c.Param("external_id"), c.GetString("user_external_id"))
+		if err == sql.ErrNoRows {
+			utils.ErrorResponse(c, 403, "not a member of this workspace")
+			c.Abort()
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to resolve workspace role: %v", err)
+			utils.ErrorResponse(c, 500, "internal server error")
+			c.Abort()
+			return
+		}
+
+		if !policy.Can(access.Role, access.Permissions, action, resource) {
+			utils.ErrorResponse(c, 403, policy.DenialMessage(action, resource))
+			c.Abort()
+			return
+		}
+
+		c.Set("workspace_role", access.Role)
+		c.Next()
+	}
+}
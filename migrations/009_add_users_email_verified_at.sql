@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+-- Accounts created before verification existed are trusted as-is
+UPDATE users SET email_verified_at = created_at;
+
+-- +migrate Down
+ALTER TABLE users DROP COLUMN email_verified_at;
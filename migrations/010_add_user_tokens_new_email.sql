@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Pending address for email change confirmations
+ALTER TABLE user_tokens ADD COLUMN new_email VARCHAR(255);
+
+-- +migrate Down
+ALTER TABLE user_tokens DROP COLUMN new_email;
@@ -0,0 +1,28 @@
+-- +migrate Up
+CREATE TABLE workspace_invitations (
+    id SERIAL PRIMARY KEY,
+    external_id VARCHAR(36) NOT NULL UNIQUE,
+    workspace_id INT NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    invited_by_id INT,
+    accepted_by_id INT,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    modified_at TIMESTAMP,
+    modified_by VARCHAR(255),
+    CONSTRAINT fk_workspace_invitations_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT fk_workspace_invitations_inviter FOREIGN KEY (invited_by_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT fk_workspace_invitations_acceptor FOREIGN KEY (accepted_by_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT chk_workspace_invitations_status CHECK (status IN ('pending', 'accepted', 'revoked', 'expired'))
+);
+
+-- At most one open invitation per address and workspace
+CREATE UNIQUE INDEX uq_workspace_invitations_pending_email ON workspace_invitations (workspace_id, LOWER(email)) WHERE status = 'pending';
+
+-- +migrate Down
+DROP TABLE workspace_invitations;
@@ -0,0 +1,35 @@
+-- +migrate Up
+CREATE TABLE workspace_join_links (
+    id SERIAL PRIMARY KEY,
+    external_id VARCHAR(36) NOT NULL UNIQUE,
+    workspace_id INT NOT NULL,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(50) NOT NULL,
+    max_uses INT,
+    use_count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_by_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    modified_at TIMESTAMP,
+    modified_by VARCHAR(255),
+    CONSTRAINT fk_workspace_join_links_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT fk_workspace_join_links_creator FOREIGN KEY (created_by_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT chk_workspace_join_links_max_uses CHECK (max_uses IS NULL OR max_uses > 0)
+);
+
+CREATE TABLE workspace_join_link_uses (
+    id SERIAL PRIMARY KEY,
+    join_link_id INT NOT NULL,
+    user_id INT NOT NULL,
+    joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_workspace_join_link_uses_link FOREIGN KEY (join_link_id) REFERENCES workspace_join_links (id) ON DELETE CASCADE,
+    CONSTRAINT fk_workspace_join_link_uses_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_workspace_join_link_uses_link_id ON workspace_join_link_uses (join_link_id);
+
+-- +migrate Down
+DROP TABLE workspace_join_link_uses;
+DROP TABLE workspace_join_links;
@@ -0,0 +1,57 @@
+-- +migrate Up
+CREATE TABLE workspace_roles (
+    id SERIAL PRIMARY KEY,
+    external_id VARCHAR(36) NOT NULL UNIQUE,
+    workspace_id INT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    description TEXT,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    is_builtin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    modified_at TIMESTAMP,
+    modified_by VARCHAR(255),
+    CONSTRAINT fk_workspace_roles_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT uq_workspace_roles_workspace_name UNIQUE (workspace_id, name)
+);
+
+-- Seed the built-in roles into every existing workspace
+INSERT INTO workspace_roles (external_id, workspace_id, name, description, permissions, is_builtin)
+SELECT md5(random()::text || clock_timestamp()::text)::uuid::text, w.id, r.name, r.description, r.permissions, TRUE
+FROM workspaces w
+CROSS JOIN (VALUES
+    ('owner', 'Full control of the workspace', ARRAY[
+        'workspace:view', 'workspace:update', 'workspace:delete', 'workspace:transfer',
+        'member:view', 'member:update', 'member:delete',
+        'role:view', 'role:manage',
+        'invitation:manage',
+        'join_link:manage',
+        'board:view', 'board:create', 'board:update', 'board:delete',
+        'task:view', 'task:create', 'task:update', 'task:delete'
+    ]),
+    ('admin', 'Manages members, boards and tasks', ARRAY[
+        'workspace:view',
+        'member:view', 'member:update', 'member:delete',
+        'role:view',
+        'invitation:manage',
+        'join_link:manage',
+        'board:view', 'board:create', 'board:update', 'board:delete',
+        'task:view', 'task:create', 'task:update', 'task:delete'
+    ]),
+    ('member', 'Works on boards and tasks', ARRAY[
+        'workspace:view',
+        'member:view',
+        'role:view',
+        'board:view', 'board:create', 'board:update',
+        'task:view', 'task:create', 'task:update', 'task:delete'
+    ])
+) AS r (name, description, permissions);
+
+-- Members must hold a role that exists in their workspace; renaming a role follows through
+ALTER TABLE workspace_members
+    ADD CONSTRAINT fk_workspace_members_role FOREIGN KEY (workspace_id, role)
+    REFERENCES workspace_roles (workspace_id, name) ON UPDATE CASCADE;
+
+-- +migrate Down
+ALTER TABLE workspace_members DROP CONSTRAINT fk_workspace_members_role;
+DROP TABLE workspace_roles;
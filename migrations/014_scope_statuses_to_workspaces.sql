@@ -0,0 +1,68 @@
+-- +migrate Up
+ALTER TABLE statuses
+    ADD COLUMN workspace_id INT,
+    ADD COLUMN board_id INT,
+    ADD CONSTRAINT fk_statuses_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_statuses_board FOREIGN KEY (board_id) REFERENCES boards (id) ON DELETE CASCADE;
+
+ALTER TABLE statuses DROP CONSTRAINT statuses_name_key;
+
+-- Give every workspace its own copy of the global statuses
+INSERT INTO statuses (external_id, workspace_id, name, color, position, active_status, created_at, created_by, modified_at, modified_by)
+SELECT md5(random()::text || clock_timestamp()::text)::uuid::text, w.id, g.name, g.color, g.position, g.active_status, g.created_at, g.created_by, g.modified_at, g.modified_by
+FROM workspaces w
+CROSS JOIN statuses g
+WHERE g.workspace_id IS NULL;
+
+-- Point tasks at the copy belonging to their board's workspace (global names were unique)
+UPDATE tasks t
+SET status_id = ws.id
+FROM boards b, statuses g, statuses ws
+WHERE t.board_id = b.id
+    AND t.status_id = g.id
+    AND g.workspace_id IS NULL
+    AND ws.workspace_id = b.workspace_id
+    AND ws.name = g.name;
+
+DELETE FROM statuses WHERE workspace_id IS NULL;
+
+-- Workspaces that still have no statuses get the default set
+INSERT INTO statuses (external_id, workspace_id, name, color, position)
+SELECT md5(random()::text || clock_timestamp()::text || d.name)::uuid::text, w.id, d.name, d.color, d.position
+FROM workspaces w
+CROSS JOIN (VALUES
+    ('To Do', '#94a3b8', 0),
+    ('In Progress', '#3b82f6', 1),
+    ('Done', '#22c55e', 2)
+) AS d (name, color, position)
+WHERE NOT EXISTS (SELECT 1 FROM statuses s WHERE s.workspace_id = w.id);
+
+ALTER TABLE statuses ALTER COLUMN workspace_id SET NOT NULL;
+
+-- Names are unique per workspace, and per board for board-only columns
+CREATE UNIQUE INDEX uq_statuses_workspace_name ON statuses (workspace_id, LOWER(name))
+    WHERE board_id IS NULL AND active_status = 1;
+CREATE UNIQUE INDEX uq_statuses_board_name ON statuses (board_id, LOWER(name))
+    WHERE board_id IS NOT NULL AND active_status = 1;
+CREATE INDEX idx_statuses_workspace_id ON statuses (workspace_id);
+
+-- +migrate Down
+DROP INDEX idx_statuses_workspace_id;
+DROP INDEX uq_statuses_board_name;
+DROP INDEX uq_statuses_workspace_name;
+
+-- Collapse the per-workspace copies back into one status per name
+UPDATE tasks t
+SET status_id = keep.id
+FROM statuses s
+JOIN (SELECT name, MIN(id) AS id FROM statuses GROUP BY name) keep ON keep.name = s.name
+WHERE t.status_id = s.id AND s.id <> keep.id;
+
+DELETE FROM statuses s
+USING (SELECT name, MIN(id) AS id FROM statuses GROUP BY name) keep
+WHERE s.name = keep.name AND s.id <> keep.id;
+
+ALTER TABLE statuses
+    DROP COLUMN board_id,
+    DROP COLUMN workspace_id,
+    ADD CONSTRAINT statuses_name_key UNIQUE (name);
@@ -0,0 +1,36 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Built-in owner and admin roles manage statuses; other roles lose the implicit access board:update gave them
+UPDATE workspace_roles
+SET permissions = array_append(permissions, 'status:manage')
+WHERE is_builtin AND name IN ('owner', 'admin') AND NOT ('status:manage' = ANY (permissions));
+
+CREATE TABLE status_audit_logs (
+    id SERIAL PRIMARY KEY,
+    status_id INT,
+    status_external_id VARCHAR(36) NOT NULL,
+    workspace_id INT NOT NULL,
+    board_id INT,
+    action VARCHAR(20) NOT NULL,
+    old_values JSONB,
+    new_values JSONB,
+    actor_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    CONSTRAINT fk_status_audit_logs_status FOREIGN KEY (status_id) REFERENCES statuses (id) ON DELETE SET NULL,
+    CONSTRAINT fk_status_audit_logs_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT fk_status_audit_logs_board FOREIGN KEY (board_id) REFERENCES boards (id) ON DELETE SET NULL,
+    CONSTRAINT fk_status_audit_logs_actor FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT chk_status_audit_logs_action CHECK (action IN ('created', 'updated', 'deleted'))
+);
+
+CREATE INDEX idx_status_audit_logs_workspace_id ON status_audit_logs (workspace_id, created_at DESC);
+
+-- +migrate Down
+DROP TABLE status_audit_logs;
+
+UPDATE workspace_roles
+SET permissions = array_remove(permissions, 'status:manage');
+
+ALTER TABLE users DROP COLUMN is_admin;
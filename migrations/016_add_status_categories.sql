@@ -0,0 +1,21 @@
+-- +migrate Up
+ALTER TABLE statuses ADD COLUMN category VARCHAR(20) NOT NULL DEFAULT 'todo';
+ALTER TABLE statuses ADD CONSTRAINT chk_statuses_category CHECK (category IN ('backlog', 'todo', 'in_progress', 'done'));
+
+-- Best guess for existing statuses from their names; anything else stays a to-do column
+UPDATE statuses SET category = 'done' WHERE LOWER(name) IN ('done', 'complete', 'completed', 'closed', 'resolved');
+UPDATE statuses SET category = 'in_progress' WHERE LOWER(name) IN ('in progress', 'doing', 'in review', 'review');
+UPDATE statuses SET category = 'backlog' WHERE LOWER(name) = 'backlog';
+
+ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMP;
+
+-- Tasks already sitting in a done column count as completed when they were last touched
+UPDATE tasks t
+SET completed_at = COALESCE(t.modified_at, t.created_at)
+FROM statuses s
+WHERE t.status_id = s.id AND s.category = 'done';
+
+-- +migrate Down
+ALTER TABLE tasks DROP COLUMN completed_at;
+ALTER TABLE statuses DROP CONSTRAINT chk_statuses_category;
+ALTER TABLE statuses DROP COLUMN category;
@@ -0,0 +1,21 @@
+-- +migrate Up
+-- Rank keys compare byte by byte, so the column must not use a locale-aware collation
+ALTER TABLE tasks ADD COLUMN rank VARCHAR(255) COLLATE "C";
+
+-- Keep the current column order: fixed-width counters sort correctly, and the trailing
+-- 'i' keeps keys from ending in '0' so there is always room to insert between them
+UPDATE tasks t
+SET rank = lpad(o.n::text, 10, '0') || 'i'
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY board_id, status_id ORDER BY position, created_at, id) AS n
+    FROM tasks
+) o
+WHERE t.id = o.id;
+
+ALTER TABLE tasks ALTER COLUMN rank SET NOT NULL;
+
+CREATE INDEX idx_tasks_board_status_rank ON tasks (board_id, status_id, rank);
+
+-- +migrate Down
+DROP INDEX idx_tasks_board_status_rank;
+ALTER TABLE tasks DROP COLUMN rank;
@@ -0,0 +1,48 @@
+-- +migrate Up
+CREATE TABLE task_comments (
+    id SERIAL PRIMARY KEY,
+    external_id VARCHAR(36) NOT NULL UNIQUE,
+    task_id INT NOT NULL,
+    author_id INT,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP,
+    active_status INT DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    modified_at TIMESTAMP,
+    modified_by VARCHAR(255),
+    CONSTRAINT fk_task_comments_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_comments_author FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_task_comments_task_id ON task_comments (task_id, created_at DESC, id DESC);
+
+-- Members mentioned in a comment, resolved when it is written so notifications can read them directly
+CREATE TABLE task_comment_mentions (
+    id SERIAL PRIMARY KEY,
+    comment_id INT NOT NULL,
+    user_id INT NOT NULL,
+    mention_text VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_task_comment_mentions_comment FOREIGN KEY (comment_id) REFERENCES task_comments (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_comment_mentions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT uq_task_comment_mentions_comment_user UNIQUE (comment_id, user_id)
+);
+
+CREATE INDEX idx_task_comment_mentions_user_id ON task_comment_mentions (user_id);
+
+-- Everyone who works on tasks may comment; built-in admins also moderate
+UPDATE workspace_roles
+SET permissions = array_append(permissions, 'comment:create')
+WHERE is_builtin AND name IN ('owner', 'admin', 'member') AND NOT ('comment:create' = ANY (permissions));
+
+UPDATE workspace_roles
+SET permissions = array_append(permissions, 'comment:moderate')
+WHERE is_builtin AND name IN ('owner', 'admin') AND NOT ('comment:moderate' = ANY (permissions));
+
+-- +migrate Down
+UPDATE workspace_roles
+SET permissions = array_remove(array_remove(permissions, 'comment:create'), 'comment:moderate');
+
+DROP TABLE task_comment_mentions;
+DROP TABLE task_comments;
@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE attachments (
+    id SERIAL PRIMARY KEY,
+    external_id VARCHAR(36) NOT NULL UNIQUE,
+    task_id INT NOT NULL,
+    uploaded_by_id INT,
+    filename VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    checksum VARCHAR(64) NOT NULL, -- Hex SHA-256 of the contents
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    modified_at TIMESTAMP,
+    modified_by VARCHAR(255),
+    CONSTRAINT fk_attachments_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_attachments_uploader FOREIGN KEY (uploaded_by_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_attachments_task_id ON attachments (task_id);
+
+-- +migrate Down
+DROP TABLE attachments;
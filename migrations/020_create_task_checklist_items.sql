@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE task_checklist_items (
+    id SERIAL PRIMARY KEY,
+    external_id VARCHAR(36) NOT NULL UNIQUE,
+    task_id INT NOT NULL,
+    content VARCHAR(1000) NOT NULL,
+    is_done BOOLEAN NOT NULL DEFAULT FALSE,
+    done_at TIMESTAMP,
+    assigned_to INT,
+    due_date TIMESTAMP,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    modified_at TIMESTAMP,
+    modified_by VARCHAR(255),
+    CONSTRAINT fk_task_checklist_items_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_checklist_items_assignee FOREIGN KEY (assigned_to) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_task_checklist_items_task_id ON task_checklist_items (task_id, position);
+
+-- +migrate Down
+DROP TABLE task_checklist_items;
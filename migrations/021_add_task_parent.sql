@@ -0,0 +1,12 @@
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN parent_task_id INT;
+ALTER TABLE tasks ADD CONSTRAINT fk_tasks_parent FOREIGN KEY (parent_task_id) REFERENCES tasks (id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_parent_not_self CHECK (parent_task_id <> id);
+
+CREATE INDEX idx_tasks_parent_task_id ON tasks (parent_task_id);
+
+-- +migrate Down
+DROP INDEX idx_tasks_parent_task_id;
+ALTER TABLE tasks DROP CONSTRAINT chk_tasks_parent_not_self;
+ALTER TABLE tasks DROP CONSTRAINT fk_tasks_parent;
+ALTER TABLE tasks DROP COLUMN parent_task_id;
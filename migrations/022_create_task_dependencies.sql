@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE task_dependencies (
+    id SERIAL PRIMARY KEY,
+    blocker_task_id INT NOT NULL,
+    blocked_task_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    CONSTRAINT fk_task_dependencies_blocker FOREIGN KEY (blocker_task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_dependencies_blocked FOREIGN KEY (blocked_task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT uq_task_dependencies UNIQUE (blocker_task_id, blocked_task_id),
+    CONSTRAINT chk_task_dependencies_not_self CHECK (blocker_task_id <> blocked_task_id)
+);
+
+CREATE INDEX idx_task_dependencies_blocked_task_id ON task_dependencies (blocked_task_id);
+
+-- +migrate Down
+DROP TABLE task_dependencies;
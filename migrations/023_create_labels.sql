@@ -0,0 +1,39 @@
+-- +migrate Up
+CREATE TABLE labels (
+    id SERIAL PRIMARY KEY,
+    external_id VARCHAR(36) NOT NULL UNIQUE,
+    workspace_id INT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    modified_at TIMESTAMP,
+    modified_by VARCHAR(255),
+    CONSTRAINT fk_labels_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX uq_labels_workspace_name ON labels (workspace_id, LOWER(name));
+
+CREATE TABLE task_labels (
+    task_id INT NOT NULL,
+    label_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    PRIMARY KEY (task_id, label_id),
+    CONSTRAINT fk_task_labels_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_labels_label FOREIGN KEY (label_id) REFERENCES labels (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_labels_label_id ON task_labels (label_id);
+
+-- Labels are lightweight, so everyone who works on tasks may manage them
+UPDATE workspace_roles
+SET permissions = array_append(permissions, 'label:manage')
+WHERE is_builtin AND name IN ('owner', 'admin', 'member') AND NOT ('label:manage' = ANY (permissions));
+
+-- +migrate Down
+UPDATE workspace_roles
+SET permissions = array_remove(permissions, 'label:manage');
+
+DROP TABLE task_labels;
+DROP TABLE labels;
@@ -0,0 +1,13 @@
+-- +migrate Up
+-- Roles ranked with members outrank nobody, so permissions that act on lower-ranked
+-- members, roles, invitations and join links never had any effect for them
+UPDATE workspace_roles
+SET permissions = ARRAY(
+    SELECT p FROM unnest(permissions) AS p
+    WHERE p NOT IN ('member:update', 'member:delete', 'role:manage', 'invitation:manage', 'join_link:manage')
+)
+WHERE name NOT IN ('owner', 'admin');
+
+-- +migrate Down
+-- The stripped permissions were inert, so there is nothing to restore
+SELECT 1;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- Deleting and transferring a workspace are owner-only and no longer grantable
+UPDATE workspace_roles
+SET permissions = array_remove(array_remove(permissions, 'workspace:delete'), 'workspace:transfer');
+
+-- +migrate Down
+UPDATE workspace_roles
+SET permissions = permissions || ARRAY['workspace:delete', 'workspace:transfer']
+WHERE is_builtin AND name = 'owner';
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type Attachment struct {
+	ID                 int        `json:"-"`
+	ExternalID         string     `json:"external_id"`
+	TaskID             int        `json:"-"`
+	UploadedByID       *int       `json:"-"`
+	UploaderExternalID *string    `json:"-"` // Not output as json, used for mapping
+	UploaderName       *string    `json:"-"` // Not output as json, used for mapping
+	Filename           string     `json:"filename"`
+	SizeBytes          int64      `json:"size_bytes"`
+	MimeType           string     `json:"mime_type"` // Sniffed from the contents, not taken from the client
+	Checksum           string     `json:"checksum"`  // Hex SHA-256
+	StorageKey         string     `json:"-"`
+	CreatedAt          time.Time  `json:"created_at"`
+	CreatedBy          *string    `json:"created_by,omitempty"`
+	ModifiedAt         *time.Time `json:"modified_at,omitempty"`
+	ModifiedBy         *string    `json:"modified_by,omitempty"`
+}
+
+type AttachmentResponse struct {
+	ExternalID     string        `json:"external_id"`
+	TaskExternalID string        `json:"task_external_id"`
+	Filename       string        `json:"filename"`
+	SizeBytes      int64         `json:"size_bytes"`
+	MimeType       string        `json:"mime_type"`
+	Checksum       string        `json:"checksum"`
+	UploadedBy     *TaskUserInfo `json:"uploaded_by"`
+	DownloadURL    string        `json:"download_url"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type ChecklistItem struct {
+	ID                 int        `json:"-"`
+	ExternalID         string     `json:"external_id"`
+	TaskID             int        `json:"-"`
+	Content            string     `json:"text"`
+	IsDone             bool       `json:"is_done"`
+	DoneAt             *time.Time `json:"done_at,omitempty"`
+	AssignedTo         *int       `json:"-"`
+	AssigneeExternalID *string    `json:"-"` // Not output as json, used for mapping
+	AssigneeName       *string    `json:"-"` // Not output as json, used for mapping
+	DueDate            *time.Time `json:"due_date,omitempty"`
+	Position           int        `json:"position"`
+	CreatedAt          time.Time  `json:"created_at"`
+	CreatedBy          *string    `json:"created_by,omitempty"`
+	ModifiedAt         *time.Time `json:"modified_at,omitempty"`
+	ModifiedBy         *string    `json:"modified_by,omitempty"`
+}
+
+type ChecklistItemResponse struct {
+	ExternalID     string        `json:"external_id"`
+	TaskExternalID string        `json:"task_external_id"`
+	Text           string        `json:"text"`
+	IsDone         bool          `json:"is_done"`
+	DoneAt         *time.Time    `json:"done_at,omitempty"`
+	AssignedTo     *TaskUserInfo `json:"assigned_to"`
+	DueDate        *time.Time    `json:"due_date,omitempty"`
+	Position       int           `json:"position"`
+	CreatedAt      time.Time     `json:"created_at"`
+	ModifiedAt     *time.Time    `json:"modified_at,omitempty"`
+}
+
+// ChecklistSummary is the progress of a task's checklist, shown on cards
+type ChecklistSummary struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// ChecklistItemRequest creates an item or replaces all of its fields; new items go to the bottom
+type ChecklistItemRequest struct {
+	Text                 string     `json:"text" binding:"required,max=1000"`
+	IsDone               bool       `json:"is_done"`
+	AssignedToExternalID *string    `json:"assigned_to_external_id"`
+	DueDate              *time.Time `json:"due_date"`
+}
+
+// ReorderChecklistRequest lists every item of a task's checklist in the new order
+type ReorderChecklistRequest struct {
+	ItemExternalIDs []string `json:"item_external_ids" binding:"required,min=1,dive,required"`
+}
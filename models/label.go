@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Label match modes for filtering board tasks by several labels
+const (
+	LabelMatchAny = "any"
+	LabelMatchAll = "all"
+)
+
+type Label struct {
+	ID          int        `json:"-"`
+	ExternalID  string     `json:"external_id"`
+	WorkspaceID int        `json:"-"`
+	Name        string     `json:"name"`
+	Color       *string    `json:"color,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CreatedBy   *string    `json:"created_by,omitempty"`
+	ModifiedAt  *time.Time `json:"modified_at,omitempty"`
+	ModifiedBy  *string    `json:"modified_by,omitempty"`
+}
+
+type LabelResponse struct {
+	ExternalID          string     `json:"external_id"`
+	WorkspaceExternalID string     `json:"workspace_external_id"`
+	Name                string     `json:"name"`
+	Color               *string    `json:"color,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	ModifiedAt          *time.Time `json:"modified_at,omitempty"`
+}
+
+// TaskLabelInfo is a label as embedded in a task
+type TaskLabelInfo struct {
+	ExternalID string  `json:"external_id"`
+	Name       string  `json:"name"`
+	Color      *string `json:"color,omitempty"`
+}
+
+type LabelRequest struct {
+	Name  string  `json:"name" binding:"required,max=50"`
+	Color *string `json:"color" binding:"omitempty,max=50"`
+}
+
+// TaskLabelRequest attaches an existing workspace label to a task
+type TaskLabelRequest struct {
+	LabelExternalID string `json:"label_external_id" binding:"required"`
+}
+
+// TaskLabelFilter narrows a board listing to tasks carrying any or all of the labels
+type TaskLabelFilter struct {
+	LabelIDs []int64
+	MatchAll bool
+}
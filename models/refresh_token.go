@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type RefreshToken struct {
+	ID           int        `json:"-"`
+	UserID       int        `json:"-"`
+	FamilyID     string     `json:"-"` // Shared by every token rotated from the same login
+	TokenHash    string     `json:"-"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	ReplacedByID *int       `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Expired      bool       `json:"-"` // Evaluated by the database clock on read
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Access token lifetime in seconds
+}
@@ -2,30 +2,53 @@ package models
 
 import "time"
 
+// Status categories tell the system what a column means regardless of its name
+const (
+	StatusCategoryBacklog    = "backlog"
+	StatusCategoryTodo       = "todo"
+	StatusCategoryInProgress = "in_progress"
+	StatusCategoryDone       = "done" // Tasks in a done column are completed
+)
+
+// Status is a task column. It belongs to a workspace, and optionally to a single board of it.
 type Status struct {
-	ID           int        `json:"-"`
-	ExternalID   string     `json:"external_id"`
-	Name         string     `json:"name"`
-	Color        *string    `json:"color,omitempty"`
-	Position     int        `json:"position"`
-	ActiveStatus int        `json:"active_status"`
-	CreatedAt    time.Time  `json:"created_at"`
-	CreatedBy    *string    `json:"created_by,omitempty"`
-	ModifiedAt   *time.Time `json:"modified_at,omitempty"`
-	ModifiedBy   *string    `json:"modified_by,omitempty"`
+	ID                  int        `json:"-"`
+	ExternalID          string     `json:"external_id"`
+	WorkspaceID         int        `json:"-"`
+	WorkspaceExternalID string     `json:"-"` // Not output as json, used for mapping
+	BoardID             *int       `json:"-"`
+	BoardExternalID     *string    `json:"-"` // Not output as json, used for mapping
+	Name                string     `json:"name"`
+	Color               *string    `json:"color,omitempty"`
+	Category            string     `json:"category"`
+	Position            int        `json:"position"`
+	ActiveStatus        int        `json:"active_status"`
+	CreatedAt           time.Time  `json:"created_at"`
+	CreatedBy           *string    `json:"created_by,omitempty"`
+	ModifiedAt          *time.Time `json:"modified_at,omitempty"`
+	ModifiedBy          *string    `json:"modified_by,omitempty"`
 }
 
 type StatusResponse struct {
-	ExternalID string     `json:"external_id"`
-	Name       string     `json:"name"`
-	Color      *string    `json:"color,omitempty"`
-	Position   int        `json:"position"`
-	CreatedAt  time.Time  `json:"created_at"`
-	ModifiedAt *time.Time `json:"modified_at,omitempty"`
+	ExternalID          string     `json:"external_id"`
+	WorkspaceExternalID string     `json:"workspace_external_id"`
+	BoardExternalID     *string    `json:"board_external_id,omitempty"` // Only set for board-specific columns
+	Name                string     `json:"name"`
+	Color               *string    `json:"color,omitempty"`
+	Category            string     `json:"category"`
+	Position            int        `json:"position"`
+	CreatedAt           time.Time  `json:"created_at"`
+	ModifiedAt          *time.Time `json:"modified_at,omitempty"`
 }
 
 type StatusRequest struct {
 	Name     string  `json:"name" binding:"required"`
 	Color    *string `json:"color"`
+	Category string  `json:"category" binding:"required,oneof=backlog todo in_progress done"`
 	Position *int    `json:"position"` // Optional input, default 0
 }
+
+// ReorderStatusesRequest lists every status of a scope in the new column order
+type ReorderStatusesRequest struct {
+	StatusExternalIDs []string `json:"status_external_ids" binding:"required,min=1,dive,required"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status audit actions
+const (
+	StatusAuditCreated = "created"
+	StatusAuditUpdated = "updated"
+	StatusAuditDeleted = "deleted"
+)
+
+// StatusSnapshot is the part of a status recorded in the audit trail
+type StatusSnapshot struct {
+	Name     string  `json:"name"`
+	Color    *string `json:"color,omitempty"`
+	Category string  `json:"category"`
+	Position int     `json:"position"`
+}
+
+type StatusAuditLog struct {
+	ID               int             `json:"-"`
+	StatusID         *int            `json:"-"`
+	StatusExternalID string          `json:"status_external_id"`
+	WorkspaceID      int             `json:"-"`
+	BoardID          *int            `json:"-"`
+	BoardExternalID  *string         `json:"-"` // Not output as json, used for mapping
+	Action           string          `json:"action"`
+	OldValues        json.RawMessage `json:"old_values,omitempty"`
+	NewValues        json.RawMessage `json:"new_values,omitempty"`
+	ActorID          *int            `json:"-"`
+	ActorExternalID  *string         `json:"-"` // Not output as json, used for mapping
+	ActorName        *string         `json:"-"` // Not output as json, used for mapping
+	CreatedAt        time.Time       `json:"created_at"`
+	CreatedBy        *string         `json:"created_by,omitempty"`
+}
+
+type StatusAuditLogResponse struct {
+	StatusExternalID string          `json:"status_external_id"`
+	BoardExternalID  *string         `json:"board_external_id,omitempty"`
+	Action           string          `json:"action"`
+	OldValues        json.RawMessage `json:"old_values,omitempty"`
+	NewValues        json.RawMessage `json:"new_values,omitempty"`
+	ActorExternalID  *string         `json:"actor_external_id,omitempty"`
+	ActorName        *string         `json:"actor_name,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
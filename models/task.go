@@ -2,11 +2,25 @@ package models
 
 import "time"
 
+// Task list states, derived from the category of each task's status
+const (
+	TaskStateOpen      = "open"
+	TaskStateCompleted = "completed"
+)
+
+// What deleting a task does to its subtasks
+const (
+	SubtaskDeleteCascade = "cascade" // Delete the whole subtree
+	SubtaskDeleteOrphan  = "orphan"  // Detach the children, which become top-level tasks
+	SubtaskDeleteBlock   = "block"   // Refuse while the task has children
+)
+
 type Task struct {
 	ID           int        `json:"-"`
 	ExternalID   string     `json:"external_id"`
 	BoardID      int        `json:"-"`
 	StatusID     int        `json:"-"`
+	ParentTaskID *int       `json:"-"` // Parent (epic) task, possibly on another board of the same workspace
 	AssignedTo   *int       `json:"-"`
 	CreatedByID  int        `json:"-"`
 	Title        string     `json:"title"`
@@ -14,6 +28,8 @@ type Task struct {
 	Priority     string     `json:"priority"`
 	DueDate      *time.Time `json:"due_date,omitempty"`
 	Position     int        `json:"position"`
+	Rank         string     `json:"rank"`                   // Sort key within the status column, see utils.RankBetween
+	CompletedAt  *time.Time `json:"completed_at,omitempty"` // Set while the task sits in a done status
 	ActiveStatus int        `json:"active_status"`
 	CreatedAt    time.Time  `json:"created_at"`
 	CreatedBy    *string    `json:"created_by,omitempty"`
@@ -22,43 +38,74 @@ type Task struct {
 }
 
 type TaskResponse struct {
-	ExternalID        string            `json:"external_id"`
-	BoardExternalID   string            `json:"board_external_id"`
-	Status            TaskStatusInfo    `json:"status"`
-	AssignedTo        *TaskAssigneeInfo `json:"assigned_to"`
-	Title             string            `json:"title"`
-	Description       *string           `json:"description,omitempty"`
-	Priority          string            `json:"priority"`
-	DueDate           *time.Time        `json:"due_date,omitempty"`
-	Position          int               `json:"position"`
-	CreatedAt         time.Time         `json:"created_at"`
-	ModifiedAt        *time.Time        `json:"modified_at,omitempty"`
+	ExternalID           string           `json:"external_id"`
+	BoardExternalID      string           `json:"board_external_id"`
+	ParentTaskExternalID *string          `json:"parent_task_external_id,omitempty"`
+	Status               TaskStatusInfo   `json:"status"`
+	AssignedTo           *TaskUserInfo    `json:"assigned_to"`
+	CreatedBy            *TaskUserInfo    `json:"created_by"`
+	Title                string           `json:"title"`
+	Description          *string          `json:"description,omitempty"`
+	Priority             string           `json:"priority"`
+	DueDate              *time.Time       `json:"due_date,omitempty"`
+	Position             int              `json:"position"`
+	Rank                 string           `json:"rank"`
+	CompletedAt          *time.Time       `json:"completed_at,omitempty"`
+	Checklist            ChecklistSummary `json:"checklist"`
+	Labels               []*TaskLabelInfo `json:"labels"`
+	IsBlocked            bool             `json:"is_blocked"`         // Some blocking task is not completed yet
+	Warnings             []string         `json:"warnings,omitempty"` // Non-fatal notes about the last change, e.g. completing a blocked task
+	CreatedAt            time.Time        `json:"created_at"`
+	ModifiedAt           *time.Time       `json:"modified_at,omitempty"`
 }
 
 type TaskStatusInfo struct {
 	ExternalID string  `json:"external_id"`
 	Name       string  `json:"name"`
 	Color      *string `json:"color,omitempty"`
+	Category   string  `json:"category"`
 }
 
-type TaskAssigneeInfo struct {
+type TaskUserInfo struct {
 	ExternalID string `json:"external_id"`
 	Name       string `json:"name"`
 }
 
 type TaskRequest struct {
-	Title                 string     `json:"title" binding:"required"`
-	Description           *string    `json:"description"`
-	Priority              string     `json:"priority" binding:"omitempty,oneof=low medium high"`
-	DueDate               *time.Time `json:"due_date"`
-	StatusExternalID      string     `json:"status_external_id" binding:"required"`
-	AssignedToExternalID  *string    `json:"assigned_to_external_id"`
+	Title                string     `json:"title" binding:"required"`
+	Description          *string    `json:"description"`
+	Priority             string     `json:"priority" binding:"omitempty,oneof=low medium high"`
+	DueDate              *time.Time `json:"due_date"`
+	StatusExternalID     string     `json:"status_external_id" binding:"required"`
+	AssignedToExternalID *string    `json:"assigned_to_external_id"`
+	ParentTaskExternalID *string    `json:"parent_task_external_id"` // Omit to make the task top-level
 }
 
 type MoveTaskStatusRequest struct {
 	StatusExternalID string `json:"status_external_id" binding:"required"`
 }
 
+// MoveTaskRequest drops a task into a status column between two neighbors. Omit both to append it
+// to the bottom of the column, or one to place it right below or above that task.
+type MoveTaskRequest struct {
+	StatusExternalID     string  `json:"status_external_id" binding:"required"`
+	AfterTaskExternalID  *string `json:"after_task_external_id"`  // Task directly above the drop point
+	BeforeTaskExternalID *string `json:"before_task_external_id"` // Task directly below the drop point
+}
+
+// TaskRollup counts a task's descendants by the category of their status
+type TaskRollup struct {
+	Total      int            `json:"total"`
+	ByCategory map[string]int `json:"by_category"`
+}
+
+// EpicResponse is a task together with the progress of everything below it
+type EpicResponse struct {
+	Task       *TaskResponse `json:"task"`
+	ChildCount int           `json:"child_count"` // Direct children only
+	Subtasks   TaskRollup    `json:"subtasks"`    // All descendants
+}
+
 type AssignTaskRequest struct {
 	AssignedToExternalID *string `json:"assigned_to_external_id"` // can be nil to unassign
 }
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type TaskComment struct {
+	ID               int               `json:"-"`
+	ExternalID       string            `json:"external_id"`
+	TaskID           int               `json:"-"`
+	AuthorID         *int              `json:"-"`
+	AuthorExternalID *string           `json:"-"` // Not output as json, used for mapping
+	AuthorName       *string           `json:"-"` // Not output as json, used for mapping
+	Body             string            `json:"body"`
+	EditedAt         *time.Time        `json:"edited_at,omitempty"`
+	ActiveStatus     int               `json:"active_status"`
+	CreatedAt        time.Time         `json:"created_at"`
+	CreatedBy        *string           `json:"created_by,omitempty"`
+	ModifiedAt       *time.Time        `json:"modified_at,omitempty"`
+	ModifiedBy       *string           `json:"modified_by,omitempty"`
+	Mentions         []*CommentMention `json:"mentions,omitempty"`
+}
+
+// CommentMention is a workspace member referenced with @name in a comment body
+type CommentMention struct {
+	UserExternalID string `json:"user_external_id"`
+	Name           string `json:"name"`
+	Text           string `json:"text"` // The mention as written, e.g. "@Bob Programmer"
+}
+
+type TaskCommentResponse struct {
+	ExternalID     string            `json:"external_id"`
+	TaskExternalID string            `json:"task_external_id"`
+	Author         *TaskUserInfo     `json:"author"` // Nil once the author's account is gone
+	Body           string            `json:"body"`
+	Mentions       []*CommentMention `json:"mentions"`
+	CreatedAt      time.Time         `json:"created_at"`
+	EditedAt       *time.Time        `json:"edited_at,omitempty"`
+}
+
+// TaskCommentListResponse is one page of a task's comments, newest first
+type TaskCommentListResponse struct {
+	Comments []*TaskCommentResponse `json:"comments"`
+	Page     int                    `json:"page"`
+	Limit    int                    `json:"limit"`
+	Total    int                    `json:"total"`
+}
+
+type TaskCommentRequest struct {
+	Body string `json:"body" binding:"required,max=10000"`
+}
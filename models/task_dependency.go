@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Blocked task completion policies, applied when a task with open blockers moves into a done status
+const (
+	BlockedCompletionWarn   = "warn"
+	BlockedCompletionRefuse = "refuse"
+)
+
+// TaskDependency records that the blocker task has to be finished before the blocked task
+type TaskDependency struct {
+	ID            int       `json:"-"`
+	BlockerTaskID int       `json:"-"`
+	BlockedTaskID int       `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+}
+
+// DependencyTaskInfo is the other end of a dependency link
+type DependencyTaskInfo struct {
+	ExternalID      string         `json:"external_id"`
+	BoardExternalID string         `json:"board_external_id"`
+	Title           string         `json:"title"`
+	Status          TaskStatusInfo `json:"status"`
+	CompletedAt     *time.Time     `json:"completed_at,omitempty"`
+	LinkedAt        time.Time      `json:"linked_at"`
+}
+
+// TaskDependenciesResponse lists both directions of a task's links
+type TaskDependenciesResponse struct {
+	BlockedBy []*DependencyTaskInfo `json:"blocked_by"`
+	Blocks    []*DependencyTaskInfo `json:"blocks"`
+}
+
+// AddDependencyRequest names a task that blocks the task in the URL
+type AddDependencyRequest struct {
+	BlockerTaskExternalID string `json:"blocker_task_external_id" binding:"required"`
+}
@@ -3,23 +3,26 @@ package models
 import "time"
 
 type User struct {
-	ID           int        `json:"id"`
-	ExternalID   string     `json:"external_id"`
-	Name         string     `json:"name"`
-	Email        string     `json:"email"`
-	Password     string     `json:"password,omitempty"`
-	AvatarURL    *string    `json:"avatar_url,omitempty"`
-	ActiveStatus int        `json:"active_status"`
-	CreatedAt    time.Time  `json:"created_at"`
-	CreatedBy    *string    `json:"created_by,omitempty"`
-	ModifiedAt   *time.Time `json:"modified_at,omitempty"`
-	ModifiedBy   *string    `json:"modified_by,omitempty"`
+	ID              int        `json:"id"`
+	ExternalID      string     `json:"external_id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Password        string     `json:"password,omitempty"`
+	AvatarURL       *string    `json:"avatar_url,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	IsAdmin         bool       `json:"is_admin"` // System-level admin, set directly in the database
+	ActiveStatus    int        `json:"active_status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CreatedBy       *string    `json:"created_by,omitempty"`
+	ModifiedAt      *time.Time `json:"modified_at,omitempty"`
+	ModifiedBy      *string    `json:"modified_by,omitempty"`
 }
 
 type RegisterRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Name            string  `json:"name" binding:"required"`
+	Email           string  `json:"email" binding:"required,email"`
+	Password        string  `json:"password" binding:"required,min=6"`
+	InvitationToken *string `json:"invitation_token"` // Optional, joins the inviting workspace on sign-up
 }
 
 type LoginRequest struct {
@@ -28,8 +31,10 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresIn    int          `json:"expires_in"`
+	User         UserResponse `json:"user"`
 }
 
 type UserResponse struct {
@@ -42,3 +47,34 @@ type UpdateProfileRequest struct {
 	Name      string  `json:"name" binding:"required"`
 	AvatarURL *string `json:"avatar_url"`
 }
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Purposes a single-use user token can be issued for
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+	UserTokenEmailChange       = "email_change"
+)
+
+type UserToken struct {
+	ID        int        `json:"-"`
+	UserID    int        `json:"-"`
+	Purpose   string     `json:"purpose"`
+	TokenHash string     `json:"-"`
+	NewEmail  *string    `json:"-"` // Only set for email change tokens
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -29,3 +29,9 @@ type WorkspaceRequest struct {
 	Name        string  `json:"name" binding:"required"`
 	Description *string `json:"description"`
 }
+
+// TransferOwnershipRequest hands a workspace to another member; the current owner confirms with their password
+type TransferOwnershipRequest struct {
+	NewOwnerExternalID string `json:"new_owner_external_id" binding:"required"`
+	Password           string `json:"password" binding:"required"`
+}
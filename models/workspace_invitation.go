@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Invitation lifecycle states
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+type WorkspaceInvitation struct {
+	ID                  int        `json:"-"`
+	ExternalID          string     `json:"external_id"`
+	WorkspaceID         int        `json:"-"`
+	WorkspaceExternalID string     `json:"-"` // Not output as json, used for mapping
+	WorkspaceName       string     `json:"-"` // Not output as json, used for mapping
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	TokenHash           string     `json:"-"`
+	Status              string     `json:"status"`
+	InvitedByID         *int       `json:"-"`
+	InvitedByExternalID *string    `json:"-"` // Not output as json, used for mapping
+	InvitedByName       *string    `json:"-"` // Not output as json, used for mapping
+	AcceptedByID        *int       `json:"-"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	AcceptedAt          *time.Time `json:"accepted_at,omitempty"`
+	Expired             bool       `json:"-"` // Evaluated by the database clock on read
+	CreatedAt           time.Time  `json:"created_at"`
+	CreatedBy           *string    `json:"created_by,omitempty"`
+	ModifiedAt          *time.Time `json:"modified_at,omitempty"`
+	ModifiedBy          *string    `json:"modified_by,omitempty"`
+}
+
+type WorkspaceInvitationResponse struct {
+	ExternalID          string     `json:"external_id"`
+	WorkspaceExternalID string     `json:"workspace_external_id"`
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	Status              string     `json:"status"`
+	InvitedByExternalID *string    `json:"invited_by_external_id,omitempty"`
+	InvitedByName       *string    `json:"invited_by_name,omitempty"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	AcceptedAt          *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// InvitationPreviewResponse is shown to whoever opens an invitation link, before accepting
+type InvitationPreviewResponse struct {
+	WorkspaceExternalID string    `json:"workspace_external_id"`
+	WorkspaceName       string    `json:"workspace_name"`
+	InvitedByName       *string   `json:"invited_by_name,omitempty"`
+	Email               string    `json:"email"`
+	Role                string    `json:"role"`
+	Status              string    `json:"status"`
+	ExpiresAt           time.Time `json:"expires_at"`
+	HasAccount          bool      `json:"has_account"` // false means the invitee should register first
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
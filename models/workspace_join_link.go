@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Join link states as reported to clients
+const (
+	JoinLinkActive    = "active"
+	JoinLinkExpired   = "expired"
+	JoinLinkExhausted = "exhausted"
+	JoinLinkRevoked   = "revoked"
+)
+
+type WorkspaceJoinLink struct {
+	ID                  int        `json:"-"`
+	ExternalID          string     `json:"external_id"`
+	WorkspaceID         int        `json:"-"`
+	WorkspaceExternalID string     `json:"-"` // Not output as json, used for mapping
+	WorkspaceName       string     `json:"-"` // Not output as json, used for mapping
+	Code                string     `json:"code"`
+	Role                string     `json:"role"`
+	MaxUses             *int       `json:"max_uses,omitempty"`
+	UseCount            int        `json:"use_count"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	RevokedAt           *time.Time `json:"revoked_at,omitempty"`
+	Expired             bool       `json:"-"` // Evaluated by the database clock on read
+	CreatedByID         *int       `json:"-"`
+	CreatedByExternalID *string    `json:"-"` // Not output as json, used for mapping
+	CreatedAt           time.Time  `json:"created_at"`
+	CreatedBy           *string    `json:"created_by,omitempty"`
+	ModifiedAt          *time.Time `json:"modified_at,omitempty"`
+	ModifiedBy          *string    `json:"modified_by,omitempty"`
+}
+
+type WorkspaceJoinLinkResponse struct {
+	ExternalID          string     `json:"external_id"`
+	WorkspaceExternalID string     `json:"workspace_external_id"`
+	Code                string     `json:"code"`
+	URL                 string     `json:"url"`
+	Role                string     `json:"role"`
+	MaxUses             *int       `json:"max_uses"`
+	UseCount            int        `json:"use_count"`
+	Status              string     `json:"status"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	RevokedAt           *time.Time `json:"revoked_at,omitempty"`
+	CreatedByExternalID *string    `json:"created_by_external_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// JoinLinkPreviewResponse tells a visitor which workspace a link leads to before joining
+type JoinLinkPreviewResponse struct {
+	WorkspaceExternalID string    `json:"workspace_external_id"`
+	WorkspaceName       string    `json:"workspace_name"`
+	Role                string    `json:"role"`
+	Status              string    `json:"status"`
+	ExpiresAt           time.Time `json:"expires_at"`
+}
+
+type JoinLinkUseResponse struct {
+	UserExternalID string    `json:"user_external_id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+type CreateJoinLinkRequest struct {
+	Role           string `json:"role" binding:"required,max=50"`
+	ExpiresInHours *int   `json:"expires_in_hours" binding:"omitempty,min=1"` // Defaults to JOIN_LINK_TTL
+	MaxUses        *int   `json:"max_uses" binding:"omitempty,min=1"`         // Omit for unlimited uses
+}
@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// Built-in workspace roles, from most to least privileged. Workspaces may define more.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
 type WorkspaceMember struct {
 	ID          int        `json:"-"`
 	WorkspaceID int        `json:"-"`
@@ -20,11 +27,14 @@ type WorkspaceMemberResponse struct {
 	Role           string `json:"role"`
 }
 
+// InviteMemberRequest targets an email address, or an existing user by external ID
 type InviteMemberRequest struct {
-	UserExternalID string `json:"user_external_id" binding:"required"`
-	Role           string `json:"role" binding:"required,oneof=owner admin member"`
+	Email          string `json:"email" binding:"omitempty,email"`
+	UserExternalID string `json:"user_external_id"`
+	Role           string `json:"role" binding:"required,max=50"`
 }
 
+// UpdateMemberRoleRequest changes a member's role; ownership only moves through a transfer
 type UpdateMemberRoleRequest struct {
-	Role string `json:"role" binding:"required,oneof=owner admin member"`
+	Role string `json:"role" binding:"required,max=50"`
 }
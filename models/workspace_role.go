@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+type WorkspaceRole struct {
+	ID          int        `json:"-"`
+	ExternalID  string     `json:"external_id"`
+	WorkspaceID int        `json:"-"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description,omitempty"`
+	Permissions []string   `json:"permissions"`
+	IsBuiltin   bool       `json:"is_builtin"`
+	MemberCount int        `json:"-"` // Not output as json, used for mapping
+	CreatedAt   time.Time  `json:"created_at"`
+	CreatedBy   *string    `json:"created_by,omitempty"`
+	ModifiedAt  *time.Time `json:"modified_at,omitempty"`
+	ModifiedBy  *string    `json:"modified_by,omitempty"`
+}
+
+type WorkspaceRoleResponse struct {
+	ExternalID  string     `json:"external_id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description,omitempty"`
+	Permissions []string   `json:"permissions"`
+	IsBuiltin   bool       `json:"is_builtin"`
+	MemberCount int        `json:"member_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ModifiedAt  *time.Time `json:"modified_at,omitempty"`
+}
+
+// WorkspaceRoleRequest creates or replaces a role; permissions are "resource:action" strings
+type WorkspaceRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// MemberAccess is a member's role in a workspace together with what that role grants
+type MemberAccess struct {
+	Role        string
+	Permissions []string
+}
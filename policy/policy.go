@@ -0,0 +1,218 @@
+// Package policy decides what each workspace role may do. It holds no state and
+// performs no lookups, so services and middleware share the exact same rules.
+// Roles are stored per workspace with their permission sets; this package ships
+// the permission catalog and the defaults the built-in roles are seeded with.
+package policy
+
+import (
+	"fmt"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+// Action is something a member attempts on a resource
+type Action string
+
+const (
+	ActionView     Action = "view"
+	ActionCreate   Action = "create"
+	ActionUpdate   Action = "update"
+	ActionDelete   Action = "delete"
+	ActionManage   Action = "manage"
+	ActionTransfer Action = "transfer"
+	ActionModerate Action = "moderate"
+)
+
+// Resource is the kind of object an action targets
+type Resource string
+
+const (
+	ResourceWorkspace  Resource = "workspace"
+	ResourceMember     Resource = "member"
+	ResourceRole       Resource = "role"
+	ResourceInvitation Resource = "invitation"
+	ResourceJoinLink   Resource = "join_link"
+	ResourceBoard      Resource = "board"
+	ResourceStatus     Resource = "status"
+	ResourceTask       Resource = "task"
+	ResourceComment    Resource = "comment"
+	ResourceLabel      Resource = "label"
+)
+
+// Permission is a resource:action pair, e.g. "board:delete"
+type Permission string
+
+// NewPermission builds the permission for an action on a resource
+func NewPermission(action Action, resource Resource) Permission {
+	return Permission(string(resource) + ":" + string(action))
+}
+
+// Resources lists every resource in display order
+var Resources = []Resource{
+	ResourceWorkspace,
+	ResourceMember,
+	ResourceRole,
+	ResourceInvitation,
+	ResourceJoinLink,
+	ResourceBoard,
+	ResourceStatus,
+	ResourceTask,
+	ResourceComment,
+	ResourceLabel,
+}
+
+// Actions lists every action that can be granted on each resource. Deleting and transferring a
+// workspace are deliberately missing: they are never grantable and only the owner may do them.
+var Actions = map[Resource][]Action{
+	ResourceWorkspace:  {ActionView, ActionUpdate},
+	ResourceMember:     {ActionView, ActionUpdate, ActionDelete},
+	ResourceRole:       {ActionView, ActionManage},
+	ResourceInvitation: {ActionManage},
+	ResourceJoinLink:   {ActionManage},
+	ResourceBoard:      {ActionView, ActionCreate, ActionUpdate, ActionDelete},
+	ResourceStatus:     {ActionManage},
+	ResourceTask:       {ActionView, ActionCreate, ActionUpdate, ActionDelete},
+	ResourceComment:    {ActionCreate, ActionModerate},
+	ResourceLabel:      {ActionManage},
+}
+
+// DefaultRolePermissions is the permission matrix the built-in roles are seeded with
+var DefaultRolePermissions = map[string][]Permission{
+	models.RoleOwner: AllPermissions(),
+	models.RoleAdmin: {
+		"workspace:view",
+		"member:view", "member:update", "member:delete",
+		"role:view",
+		"invitation:manage",
+		"join_link:manage",
+		"board:view", "board:create", "board:update", "board:delete",
+		"status:manage",
+		"task:view", "task:create", "task:update", "task:delete",
+		"comment:create", "comment:moderate",
+		"label:manage",
+	},
+	models.RoleMember: {
+		"workspace:view",
+		"member:view",
+		"role:view",
+		"board:view", "board:create", "board:update",
+		"task:view", "task:create", "task:update", "task:delete",
+		"comment:create",
+		"label:manage",
+	},
+}
+
+// BuiltinRoles lists the roles every workspace starts with, most privileged first
+var BuiltinRoles = []string{models.RoleOwner, models.RoleAdmin, models.RoleMember}
+
+// roleRank orders the built-in roles; a higher rank may manage every lower one
+var roleRank = map[string]int{
+	models.RoleOwner:  3,
+	models.RoleAdmin:  2,
+	models.RoleMember: 1,
+}
+
+// Can reports whether a member holding role and its granted permissions may perform an
+// action on a resource. The owner can always do everything, so a workspace cannot lock itself out.
+// Everyone else needs a grantable permission, so stale grants of owner-only actions count for nothing.
+func Can(role string, granted []string, action Action, resource Resource) bool {
+	if role == models.RoleOwner {
+		return true
+	}
+	if !isGrantable(action, resource) {
+		return false
+	}
+	want := string(NewPermission(action, resource))
+	for _, p := range granted {
+		if p == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Outranks reports whether the actor's role is strictly above the other role.
+// Admins therefore cannot act on other admins, and nobody can grant their own level.
+// Custom roles rank with members.
+func Outranks(actorRole, otherRole string) bool {
+	return rank(actorRole) > rank(otherRole)
+}
+
+// RankedPermissions only take effect against roles the holder outranks, so they are
+// meaningless for roles that rank with members and cannot be granted to them
+var RankedPermissions = []Permission{
+	"member:update", "member:delete",
+	"role:manage",
+	"invitation:manage",
+	"join_link:manage",
+}
+
+// CanHold reports whether a role may be granted a permission. Roles that outrank
+// nobody cannot hold the ranked permissions.
+func CanHold(role string, p Permission) bool {
+	for _, ranked := range RankedPermissions {
+		if p == ranked {
+			return rank(role) > rank(models.RoleMember)
+		}
+	}
+	return true
+}
+
+// IsBuiltinRole reports whether a role name is one of the roles seeded into every workspace
+func IsBuiltinRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// IsPermission reports whether p names an action that exists on its resource
+func IsPermission(p string) bool {
+	for _, known := range AllPermissions() {
+		if string(known) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// AllPermissions lists every grantable permission in display order
+func AllPermissions() []Permission {
+	var perms []Permission
+	for _, resource := range Resources {
+		for _, action := range Actions[resource] {
+			perms = append(perms, NewPermission(action, resource))
+		}
+	}
+	return perms
+}
+
+// DenialMessage explains a refused action in words suitable for an API error
+func DenialMessage(action Action, resource Resource) string {
+	var label string
+	switch resource {
+	case ResourceWorkspace:
+		label = "this workspace"
+	case ResourceJoinLink:
+		label = "join links"
+	case ResourceStatus:
+		label = "statuses"
+	default:
+		label = string(resource) + "s"
+	}
+	return fmt.Sprintf("your role does not allow you to %s %s", action, label)
+}
+
+func isGrantable(action Action, resource Resource) bool {
+	for _, a := range Actions[resource] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func rank(role string) int {
+	if r, ok := roleRank[role]; ok {
+		return r
+	}
+	return roleRank[models.RoleMember]
+}
@@ -0,0 +1,231 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+// allActions lists every action, including ones that cannot be granted on some resources
+var allActions = []Action{
+	ActionView,
+	ActionCreate,
+	ActionUpdate,
+	ActionDelete,
+	ActionManage,
+	ActionTransfer,
+	ActionModerate,
+}
+
+// expectedMatrix spells out the built-in permission matrix independently of DefaultRolePermissions,
+// so an accidental change to the defaults fails here. The owner is checked separately.
+var expectedMatrix = map[string]map[Resource][]Action{
+	models.RoleAdmin: {
+		ResourceWorkspace:  {ActionView},
+		ResourceMember:     {ActionView, ActionUpdate, ActionDelete},
+		ResourceRole:       {ActionView},
+		ResourceInvitation: {ActionManage},
+		ResourceJoinLink:   {ActionManage},
+		ResourceBoard:      {ActionView, ActionCreate, ActionUpdate, ActionDelete},
+		ResourceStatus:     {ActionManage},
+		ResourceTask:       {ActionView, ActionCreate, ActionUpdate, ActionDelete},
+		ResourceComment:    {ActionCreate, ActionModerate},
+		ResourceLabel:      {ActionManage},
+	},
+	models.RoleMember: {
+		ResourceWorkspace: {ActionView},
+		ResourceMember:    {ActionView},
+		ResourceRole:      {ActionView},
+		ResourceBoard:     {ActionView, ActionCreate, ActionUpdate},
+		ResourceTask:      {ActionView, ActionCreate, ActionUpdate, ActionDelete},
+		ResourceComment:   {ActionCreate},
+		ResourceLabel:     {ActionManage},
+	},
+}
+
+func defaultGrants(role string) []string {
+	var granted []string
+	for _, p := range DefaultRolePermissions[role] {
+		granted = append(granted, string(p))
+	}
+	return granted
+}
+
+func allows(actions []Action, action Action) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCanBuiltinRoles(t *testing.T) {
+	for _, role := range []string{models.RoleAdmin, models.RoleMember} {
+		granted := defaultGrants(role)
+		for _, resource := range Resources {
+			for _, action := range allActions {
+				want := allows(expectedMatrix[role][resource], action)
+				t.Run(role+"/"+string(NewPermission(action, resource)), func(t *testing.T) {
+					if got := Can(role, granted, action, resource); got != want {
+						t.Errorf("Can(%s, %s, %s) = %v, want %v", role, action, resource, got, want)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestCanOwnerAlwaysAllowed(t *testing.T) {
+	grants := map[string][]string{
+		"default grants": defaultGrants(models.RoleOwner),
+		"no grants":      nil,
+	}
+	for name, granted := range grants {
+		for _, resource := range Resources {
+			for _, action := range allActions {
+				t.Run(name+"/"+string(NewPermission(action, resource)), func(t *testing.T) {
+					if !Can(models.RoleOwner, granted, action, resource) {
+						t.Errorf("Can(owner, %s, %s) = false, want true", action, resource)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestCanCustomRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		granted []string
+	}{
+		{"viewer", "viewer", []string{"workspace:view", "member:view", "board:view", "task:view"}},
+		{"board manager", "board-manager", []string{"workspace:view", "board:view", "board:create", "board:update", "board:delete", "status:manage"}},
+		{"commenter", "commenter", []string{"task:view", "comment:create"}},
+		{"no permissions", "guest", nil},
+		{"unknown permission is inert", "odd", []string{"task:manage", "board"}},
+		{"owner-only permissions are inert", "co-owner", []string{"workspace:view", "workspace:delete", "workspace:transfer"}},
+	}
+
+	for _, tt := range tests {
+		granted := map[string]bool{}
+		for _, p := range tt.granted {
+			granted[p] = true
+		}
+		for _, resource := range Resources {
+			for _, action := range allActions {
+				p := string(NewPermission(action, resource))
+				want := granted[p] && IsPermission(p)
+				t.Run(tt.name+"/"+p, func(t *testing.T) {
+					if got := Can(tt.role, tt.granted, action, resource); got != want {
+						t.Errorf("Can(%s, %v, %s, %s) = %v, want %v", tt.role, tt.granted, action, resource, got, want)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestOutranks(t *testing.T) {
+	roles := []string{models.RoleOwner, models.RoleAdmin, models.RoleMember, "viewer", ""}
+	// Custom and unknown roles rank with members
+	level := map[string]int{
+		models.RoleOwner:  3,
+		models.RoleAdmin:  2,
+		models.RoleMember: 1,
+		"viewer":          1,
+		"":                1,
+	}
+
+	for _, actor := range roles {
+		for _, other := range roles {
+			want := level[actor] > level[other]
+			t.Run(actor+">"+other, func(t *testing.T) {
+				if got := Outranks(actor, other); got != want {
+					t.Errorf("Outranks(%q, %q) = %v, want %v", actor, other, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestCanHold(t *testing.T) {
+	ranked := map[Permission]bool{}
+	for _, p := range RankedPermissions {
+		ranked[p] = true
+	}
+
+	for _, role := range []string{models.RoleOwner, models.RoleAdmin, models.RoleMember, "viewer"} {
+		for _, p := range AllPermissions() {
+			want := !ranked[p] || role == models.RoleOwner || role == models.RoleAdmin
+			t.Run(role+"/"+string(p), func(t *testing.T) {
+				if got := CanHold(role, p); got != want {
+					t.Errorf("CanHold(%s, %s) = %v, want %v", role, p, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestWorkspaceDeleteAndTransferAreOwnerOnly(t *testing.T) {
+	ownerOnly := []Action{ActionDelete, ActionTransfer}
+	granted := []string{"workspace:view", "workspace:update", "workspace:delete", "workspace:transfer"}
+
+	for _, action := range ownerOnly {
+		p := NewPermission(action, ResourceWorkspace)
+		if IsPermission(string(p)) {
+			t.Errorf("%s is grantable", p)
+		}
+		for _, known := range AllPermissions() {
+			if known == p {
+				t.Errorf("AllPermissions lists %s", p)
+			}
+		}
+
+		for _, role := range []string{models.RoleAdmin, models.RoleMember, "co-owner"} {
+			if Can(role, granted, action, ResourceWorkspace) {
+				t.Errorf("Can(%s, %v, %s, workspace) = true, want false", role, granted, action)
+			}
+		}
+		if !Can(models.RoleOwner, nil, action, ResourceWorkspace) {
+			t.Errorf("Can(owner, %s, workspace) = false, want true", action)
+		}
+	}
+}
+
+func TestDefaultRolePermissionsAreKnown(t *testing.T) {
+	for _, role := range BuiltinRoles {
+		for _, p := range DefaultRolePermissions[role] {
+			if !IsPermission(string(p)) {
+				t.Errorf("%s is seeded with unknown permission %s", role, p)
+			}
+			if !CanHold(role, p) {
+				t.Errorf("%s is seeded with %s, which it cannot hold", role, p)
+			}
+		}
+	}
+}
+
+func TestIsPermission(t *testing.T) {
+	tests := []struct {
+		p    string
+		want bool
+	}{
+		{"task:view", true},
+		{"label:manage", true},
+		{"comment:moderate", true},
+		{"task:manage", false},
+		{"workspace:delete", false},
+		{"workspace:transfer", false},
+		{"workspace:moderate", false},
+		{"task", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPermission(tt.p); got != tt.want {
+			t.Errorf("IsPermission(%q) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
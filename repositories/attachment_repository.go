@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+type AttachmentRepository struct {
+	DB *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{DB: db}
+}
+
+// attachmentQuery selects an attachment joined with its uploader
+const attachmentQuery = `
+	SELECT
+		a.id, a.external_id, a.task_id, a.uploaded_by_id, u.external_id, u.name, a.filename, a.size_bytes,
+		a.mime_type, a.checksum, a.storage_key, a.created_at, a.created_by, a.modified_at, a.modified_by
+	FROM attachments a
+	LEFT JOIN users u ON a.uploaded_by_id = u.id
+`
+
+// scanAttachment maps a row selected with attachmentQuery into an Attachment
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	a := &models.Attachment{}
+	err := row.Scan(
+		&a.ID,
+		&a.ExternalID,
+		&a.TaskID,
+		&a.UploadedByID,
+		&a.UploaderExternalID,
+		&a.UploaderName,
+		&a.Filename,
+		&a.SizeBytes,
+		&a.MimeType,
+		&a.Checksum,
+		&a.StorageKey,
+		&a.CreatedAt,
+		&a.CreatedBy,
+		&a.ModifiedAt,
+		&a.ModifiedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// CreateAttachment records an attachment whose blob has already been stored
+func (r *AttachmentRepository) CreateAttachment(a *models.Attachment) error {
+	query := `
+		INSERT INTO attachments (external_id, task_id, uploaded_by_id, filename, size_bytes, mime_type, checksum, storage_key, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+	return r.DB.QueryRow(
+		query,
+		a.ExternalID,
+		a.TaskID,
+		a.UploadedByID,
+		a.Filename,
+		a.SizeBytes,
+		a.MimeType,
+		a.Checksum,
+		a.StorageKey,
+		a.CreatedBy,
+	).Scan(&a.ID, &a.CreatedAt)
+}
+
+// GetAttachmentsByTaskID lists a task's attachments, newest first
+func (r *AttachmentRepository) GetAttachmentsByTaskID(taskID int) ([]*models.Attachment, error) {
+	query := attachmentQuery + `
+		WHERE a.task_id = $1
+		ORDER BY a.created_at DESC, a.id DESC
+	`
+	rows, err := r.DB.Query(query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []*models.Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+// GetAttachmentByExternalID retrieves an attachment of a task
+func (r *AttachmentRepository) GetAttachmentByExternalID(taskID int, externalID string) (*models.Attachment, error) {
+	query := attachmentQuery + `
+		WHERE a.task_id = $1 AND a.external_id = $2
+	`
+	return scanAttachment(r.DB.QueryRow(query, taskID, externalID))
+}
+
+// DeleteAttachment removes an attachment record; the caller deletes the blob afterwards
+func (r *AttachmentRepository) DeleteAttachment(id int) error {
+	_, err := r.DB.Exec(`DELETE FROM attachments WHERE id = $1`, id)
+	return err
+}
+
+// deleteAttachmentsReturningKeys removes the attachments of the tasks matched by taskFilter within tx and
+// returns their storage keys, so the blobs can be deleted once the surrounding delete commits.
+// The tasks must already be locked, which keeps concurrent uploads from slipping in.
+func deleteAttachmentsReturningKeys(tx *sql.Tx, taskFilter string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(`DELETE FROM attachments WHERE task_id IN (`+taskFilter+`) RETURNING storage_key`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
@@ -89,6 +89,33 @@ func (r *BoardRepository) GetBoardByExternalID(externalID string) (*models.Board
 	return b, nil
 }
 
+// GetBoardByID retrieves a single board by its internal ID
+func (r *BoardRepository) GetBoardByID(id int) (*models.Board, error) {
+	b := &models.Board{}
+	query := `
+		SELECT b.id, b.external_id, b.workspace_id, b.created_by_id, b.name, b.description, b.active_status, b.created_at, b.modified_at, w.external_id
+		FROM boards b
+		JOIN workspaces w ON b.workspace_id = w.id
+		WHERE b.id = $1 AND b.active_status = 1
+	`
+	err := r.DB.QueryRow(query, id).Scan(
+		&b.ID,
+		&b.ExternalID,
+		&b.WorkspaceID,
+		&b.CreatedByID,
+		&b.Name,
+		&b.Description,
+		&b.ActiveStatus,
+		&b.CreatedAt,
+		&b.ModifiedAt,
+		&b.WorkspaceExternalID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
 // UpdateBoard updates the name and description of a board
 func (r *BoardRepository) UpdateBoard(b *models.Board) error {
 	query := `
@@ -100,9 +127,26 @@ func (r *BoardRepository) UpdateBoard(b *models.Board) error {
 	return r.DB.QueryRow(query, b.Name, b.Description, b.ID).Scan(&b.ModifiedAt)
 }
 
-// DeleteBoard hard deletes a board
-func (r *BoardRepository) DeleteBoard(id int) error {
-	query := `DELETE FROM boards WHERE id = $1`
-	_, err := r.DB.Exec(query, id)
-	return err
+// DeleteBoard hard deletes a board and returns the storage keys of its tasks' attachments for blob cleanup
+func (r *BoardRepository) DeleteBoard(id int) ([]string, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT 1 FROM tasks WHERE board_id = $1 FOR UPDATE`, id); err != nil {
+		return nil, err
+	}
+
+	keys, err := deleteAttachmentsReturningKeys(tx, `SELECT id FROM tasks WHERE board_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM boards WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+
+	return keys, tx.Commit()
 }
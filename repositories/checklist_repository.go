@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+// ErrChecklistOrderMismatch means a reorder did not list exactly the task's checklist items
+var ErrChecklistOrderMismatch = errors.New("reorder must list every checklist item of the task")
+
+type ChecklistRepository struct {
+	DB *sql.DB
+}
+
+func NewChecklistRepository(db *sql.DB) *ChecklistRepository {
+	return &ChecklistRepository{DB: db}
+}
+
+// checklistItemQuery selects a checklist item joined with its assignee
+const checklistItemQuery = `
+	SELECT
+		ci.id, ci.external_id, ci.task_id, ci.content, ci.is_done, ci.done_at, ci.assigned_to,
+		u.external_id, u.name, ci.due_date, ci.position, ci.created_at, ci.created_by, ci.modified_at, ci.modified_by
+	FROM task_checklist_items ci
+	LEFT JOIN users u ON ci.assigned_to = u.id
+`
+
+// scanChecklistItem maps a row selected with checklistItemQuery into a ChecklistItem
+func scanChecklistItem(row rowScanner) (*models.ChecklistItem, error) {
+	item := &models.ChecklistItem{}
+	err := row.Scan(
+		&item.ID,
+		&item.ExternalID,
+		&item.TaskID,
+		&item.Content,
+		&item.IsDone,
+		&item.DoneAt,
+		&item.AssignedTo,
+		&item.AssigneeExternalID,
+		&item.AssigneeName,
+		&item.DueDate,
+		&item.Position,
+		&item.CreatedAt,
+		&item.CreatedBy,
+		&item.ModifiedAt,
+		&item.ModifiedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// CreateItem appends an item to the bottom of its task's checklist
+func (r *ChecklistRepository) CreateItem(item *models.ChecklistItem) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the task so concurrent appends cannot pick the same position
+	if _, err := tx.Exec(`SELECT id FROM tasks WHERE id = $1 FOR UPDATE`, item.TaskID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO task_checklist_items (external_id, task_id, content, is_done, done_at, assigned_to, due_date, position, created_by)
+		SELECT $1, $2, $3, $4, CASE WHEN $4 THEN NOW() END, $5, $6, COALESCE(MAX(position) + 1, 0), $7
+		FROM task_checklist_items WHERE task_id = $2
+		RETURNING id, done_at, position, created_at
+	`
+	err = tx.QueryRow(
+		query,
+		item.ExternalID,
+		item.TaskID,
+		item.Content,
+		item.IsDone,
+		item.AssignedTo,
+		item.DueDate,
+		item.CreatedBy,
+	).Scan(&item.ID, &item.DoneAt, &item.Position, &item.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetItemsByTaskID lists a task's checklist in order
+func (r *ChecklistRepository) GetItemsByTaskID(taskID int) ([]*models.ChecklistItem, error) {
+	query := checklistItemQuery + `
+		WHERE ci.task_id = $1
+		ORDER BY ci.position ASC, ci.id ASC
+	`
+	rows, err := r.DB.Query(query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.ChecklistItem
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetItemByExternalID retrieves a checklist item of a task
+func (r *ChecklistRepository) GetItemByExternalID(taskID int, externalID string) (*models.ChecklistItem, error) {
+	query := checklistItemQuery + `
+		WHERE ci.task_id = $1 AND ci.external_id = $2
+	`
+	return scanChecklistItem(r.DB.QueryRow(query, taskID, externalID))
+}
+
+// UpdateItem saves an item's fields. done_at is stamped when the item is ticked and cleared when unticked.
+func (r *ChecklistRepository) UpdateItem(item *models.ChecklistItem) error {
+	query := `
+		UPDATE task_checklist_items
+		SET content = $1,
+			done_at = CASE WHEN NOT $2 THEN NULL WHEN is_done THEN done_at ELSE NOW() END,
+			is_done = $2,
+			assigned_to = $3,
+			due_date = $4,
+			modified_at = NOW(),
+			modified_by = $5
+		WHERE id = $6
+		RETURNING done_at, modified_at
+	`
+	return r.DB.QueryRow(query, item.Content, item.IsDone, item.AssignedTo, item.DueDate, item.ModifiedBy, item.ID).
+		Scan(&item.DoneAt, &item.ModifiedAt)
+}
+
+// DeleteItem removes a checklist item
+func (r *ChecklistRepository) DeleteItem(id int) error {
+	_, err := r.DB.Exec(`DELETE FROM task_checklist_items WHERE id = $1`, id)
+	return err
+}
+
+// ReorderItems sets the order of a task's whole checklist in one transaction
+func (r *ChecklistRepository) ReorderItems(taskID int, externalIDs []string, modifiedBy string) ([]*models.ChecklistItem, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the task first, the same as appends, then read its items
+	if _, err := tx.Exec(`SELECT id FROM tasks WHERE id = $1 FOR UPDATE`, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(checklistItemQuery+`WHERE ci.task_id = $1`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]*models.ChecklistItem)
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		current[item.ExternalID] = item
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(externalIDs) != len(current) {
+		return nil, ErrChecklistOrderMismatch
+	}
+
+	updateQuery := `
+		UPDATE task_checklist_items
+		SET position = $1, modified_at = NOW(), modified_by = $2
+		WHERE id = $3
+		RETURNING modified_at
+	`
+	items := make([]*models.ChecklistItem, 0, len(externalIDs))
+	for i, extID := range externalIDs {
+		item, ok := current[extID]
+		if !ok {
+			return nil, ErrChecklistOrderMismatch
+		}
+		delete(current, extID)
+		items = append(items, item)
+
+		if item.Position == i {
+			continue
+		}
+		item.Position = i
+		item.ModifiedBy = &modifiedBy
+		if err := tx.QueryRow(updateQuery, item.Position, modifiedBy, item.ID).Scan(&item.ModifiedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/lib/pq"
+)
+
+type LabelRepository struct {
+	DB *sql.DB
+}
+
+func NewLabelRepository(db *sql.DB) *LabelRepository {
+	return &LabelRepository{DB: db}
+}
+
+// labelQuery selects a label's columns
+const labelQuery = `
+	SELECT id, external_id, workspace_id, name, color, created_at, created_by, modified_at, modified_by
+	FROM labels
+`
+
+// scanLabel maps a row selected with labelQuery into a Label
+func scanLabel(row rowScanner) (*models.Label, error) {
+	l := &models.Label{}
+	err := row.Scan(
+		&l.ID,
+		&l.ExternalID,
+		&l.WorkspaceID,
+		&l.Name,
+		&l.Color,
+		&l.CreatedAt,
+		&l.CreatedBy,
+		&l.ModifiedAt,
+		&l.ModifiedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (r *LabelRepository) queryLabels(query string, args ...interface{}) ([]*models.Label, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var labels []*models.Label
+	for rows.Next() {
+		l, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, l)
+	}
+	return labels, rows.Err()
+}
+
+// CreateLabel inserts a workspace label; names are unique per workspace, ignoring case
+func (r *LabelRepository) CreateLabel(l *models.Label) error {
+	query := `
+		INSERT INTO labels (external_id, workspace_id, name, color, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return r.DB.QueryRow(query, l.ExternalID, l.WorkspaceID, l.Name, l.Color, l.CreatedBy).Scan(&l.ID, &l.CreatedAt)
+}
+
+// GetLabelsByWorkspaceID lists a workspace's labels by name
+func (r *LabelRepository) GetLabelsByWorkspaceID(workspaceID int) ([]*models.Label, error) {
+	return r.queryLabels(labelQuery+`
+		WHERE workspace_id = $1
+		ORDER BY LOWER(name) ASC, id ASC
+	`, workspaceID)
+}
+
+// GetLabelByExternalID retrieves a label of a workspace
+func (r *LabelRepository) GetLabelByExternalID(workspaceID int, externalID string) (*models.Label, error) {
+	query := labelQuery + `
+		WHERE workspace_id = $1 AND external_id = $2
+	`
+	return scanLabel(r.DB.QueryRow(query, workspaceID, externalID))
+}
+
+// GetLabelsByExternalIDs resolves several labels of a workspace at once; unknown IDs are left out
+func (r *LabelRepository) GetLabelsByExternalIDs(workspaceID int, externalIDs []string) ([]*models.Label, error) {
+	return r.queryLabels(labelQuery+`
+		WHERE workspace_id = $1 AND external_id = ANY($2)
+	`, workspaceID, pq.Array(externalIDs))
+}
+
+// UpdateLabel renames or recolors a label
+func (r *LabelRepository) UpdateLabel(l *models.Label) error {
+	query := `
+		UPDATE labels
+		SET name = $1, color = $2, modified_at = NOW(), modified_by = $3
+		WHERE id = $4
+		RETURNING modified_at
+	`
+	return r.DB.QueryRow(query, l.Name, l.Color, l.ModifiedBy, l.ID).Scan(&l.ModifiedAt)
+}
+
+// DeleteLabel removes a label; it disappears from every task through the cascading foreign key
+func (r *LabelRepository) DeleteLabel(id int) error {
+	_, err := r.DB.Exec(`DELETE FROM labels WHERE id = $1`, id)
+	return err
+}
+
+// AddTaskLabel attaches a label to a task; attaching it twice is a no-op
+func (r *LabelRepository) AddTaskLabel(taskID, labelID int, createdBy string) error {
+	query := `
+		INSERT INTO task_labels (task_id, label_id, created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (task_id, label_id) DO NOTHING
+	`
+	_, err := r.DB.Exec(query, taskID, labelID, createdBy)
+	return err
+}
+
+// RemoveTaskLabel detaches a label from a task, returning sql.ErrNoRows when it was not attached
+func (r *LabelRepository) RemoveTaskLabel(taskID, labelID int) error {
+	res, err := r.DB.Exec(`DELETE FROM task_labels WHERE task_id = $1 AND label_id = $2`, taskID, labelID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
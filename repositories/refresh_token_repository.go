@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+type RefreshTokenRepository struct {
+	DB *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{DB: db}
+}
+
+// CreateRefreshToken stores a new hashed refresh token that expires after ttl
+func (r *RefreshTokenRepository) CreateRefreshToken(rt *models.RefreshToken, ttl time.Duration) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		RETURNING id, expires_at, created_at
+	`
+	return r.DB.QueryRow(query, rt.UserID, rt.FamilyID, rt.TokenHash, int64(ttl.Seconds())).
+		Scan(&rt.ID, &rt.ExpiresAt, &rt.CreatedAt)
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by its hash, including revoked and expired ones
+func (r *RefreshTokenRepository) GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	rt := &models.RefreshToken{}
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, replaced_by_id, created_at, expires_at <= NOW()
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+	err := r.DB.QueryRow(query, tokenHash).Scan(
+		&rt.ID,
+		&rt.UserID,
+		&rt.FamilyID,
+		&rt.TokenHash,
+		&rt.ExpiresAt,
+		&rt.RevokedAt,
+		&rt.ReplacedByID,
+		&rt.CreatedAt,
+		&rt.Expired,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// RotateRefreshToken revokes the current token and stores its replacement in one transaction.
+// It returns false when the current token was already revoked, e.g. by a concurrent refresh.
+func (r *RefreshTokenRepository) RotateRefreshToken(current *models.RefreshToken, next *models.RefreshToken, ttl time.Duration) (bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Claim the current token; only one caller can win this update
+	res, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, current.ID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	insertQuery := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		RETURNING id, expires_at, created_at
+	`
+	err = tx.QueryRow(insertQuery, next.UserID, next.FamilyID, next.TokenHash, int64(ttl.Seconds())).
+		Scan(&next.ID, &next.ExpiresAt, &next.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`UPDATE refresh_tokens SET replaced_by_id = $1 WHERE id = $2`, next.ID, current.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RevokeFamily revokes every still-active token descended from the same login
+func (r *RefreshTokenRepository) RevokeFamily(familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := r.DB.Exec(query, familyID)
+	return err
+}
+
+// RevokeAllForUser revokes every active refresh token of a user, ending all sessions
+func (r *RefreshTokenRepository) RevokeAllForUser(userID int) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := r.DB.Exec(query, userID)
+	return err
+}
+
+// RevokeAllForUserExcept revokes every session of a user other than the given family
+func (r *RefreshTokenRepository) RevokeAllForUserExcept(userID int, keepFamilyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL`
+	_, err := r.DB.Exec(query, userID, keepFamilyID)
+	return err
+}
@@ -0,0 +1,6 @@
+package repositories
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows so scan helpers can serve single and list queries
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
@@ -2,10 +2,20 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 
 	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/utils"
+	"github.com/lib/pq"
 )
 
+// ErrStatusOrderMismatch is returned when a reorder list does not name exactly the statuses of its scope
+var ErrStatusOrderMismatch = errors.New("status order must list every status of the scope exactly once")
+
+// ErrStatusInUse is returned when deleting a status that tasks still reference without migrating them
+var ErrStatusInUse = errors.New("status is in use by one or more tasks")
+
 type StatusRepository struct {
 	DB *sql.DB
 }
@@ -14,26 +24,45 @@ func NewStatusRepository(db *sql.DB) *StatusRepository {
 	return &StatusRepository{DB: db}
 }
 
-// CreateStatus inserts a new status into the database
-func (r *StatusRepository) CreateStatus(status *models.Status) error {
-	query := `
-		INSERT INTO statuses (external_id, name, color, position)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at
-	`
-	return r.DB.QueryRow(query, status.ExternalID, status.Name, status.Color, status.Position).
-		Scan(&status.ID, &status.CreatedAt)
+// statusQuery selects a status with the external IDs of its workspace and board
+const statusQuery = `
+	SELECT
+		s.id, s.external_id, s.workspace_id, w.external_id, s.board_id, b.external_id,
+		s.name, s.color, s.category, s.position, s.active_status, s.created_at, s.created_by, s.modified_at, s.modified_by
+	FROM statuses s
+	JOIN workspaces w ON s.workspace_id = w.id
+	LEFT JOIN boards b ON s.board_id = b.id
+`
+
+// scanStatus maps a row selected with statusQuery into a Status
+func scanStatus(row rowScanner) (*models.Status, error) {
+	s := &models.Status{}
+	err := row.Scan(
+		&s.ID,
+		&s.ExternalID,
+		&s.WorkspaceID,
+		&s.WorkspaceExternalID,
+		&s.BoardID,
+		&s.BoardExternalID,
+		&s.Name,
+		&s.Color,
+		&s.Category,
+		&s.Position,
+		&s.ActiveStatus,
+		&s.CreatedAt,
+		&s.CreatedBy,
+		&s.ModifiedAt,
+		&s.ModifiedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
-// GetAllStatuses retrieves all active statuses safely
-func (r *StatusRepository) GetAllStatuses() ([]*models.Status, error) {
-	query := `
-		SELECT id, external_id, name, color, position, active_status, created_at, modified_at
-		FROM statuses
-		WHERE active_status = 1
-		ORDER BY position ASC
-	`
-	rows, err := r.DB.Query(query)
+// queryStatuses runs a statusQuery-based listing
+func (r *StatusRepository) queryStatuses(query string, args ...interface{}) ([]*models.Status, error) {
+	rows, err := r.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -41,17 +70,8 @@ func (r *StatusRepository) GetAllStatuses() ([]*models.Status, error) {
 
 	var statuses []*models.Status
 	for rows.Next() {
-		s := &models.Status{}
-		if err := rows.Scan(
-			&s.ID,
-			&s.ExternalID,
-			&s.Name,
-			&s.Color,
-			&s.Position,
-			&s.ActiveStatus,
-			&s.CreatedAt,
-			&s.ModifiedAt,
-		); err != nil {
+		s, err := scanStatus(rows)
+		if err != nil {
 			return nil, err
 		}
 		statuses = append(statuses, s)
@@ -59,74 +79,398 @@ func (r *StatusRepository) GetAllStatuses() ([]*models.Status, error) {
 	return statuses, nil
 }
 
+// CreateStatus inserts a new status and records it in the audit trail
+func (r *StatusRepository) CreateStatus(status *models.Status, actorID int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO statuses (external_id, workspace_id, board_id, name, color, category, position, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(
+		query,
+		status.ExternalID,
+		status.WorkspaceID,
+		status.BoardID,
+		status.Name,
+		status.Color,
+		status.Category,
+		status.Position,
+		status.CreatedBy,
+	).Scan(&status.ID, &status.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := recordStatusAudit(tx, status, models.StatusAuditCreated, nil, snapshotStatus(status), &actorID, status.CreatedBy); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetStatusesByWorkspaceID retrieves the active workspace-wide statuses, excluding board-only ones
+func (r *StatusRepository) GetStatusesByWorkspaceID(workspaceID int) ([]*models.Status, error) {
+	query := statusQuery + `
+		WHERE s.workspace_id = $1 AND s.board_id IS NULL AND s.active_status = 1
+		ORDER BY s.position ASC, s.id ASC
+	`
+	return r.queryStatuses(query, workspaceID)
+}
+
+// GetStatusesByBoardID retrieves the active statuses a board can use: its workspace's, followed by its own
+func (r *StatusRepository) GetStatusesByBoardID(boardID, workspaceID int) ([]*models.Status, error) {
+	query := statusQuery + `
+		WHERE s.workspace_id = $1 AND (s.board_id IS NULL OR s.board_id = $2) AND s.active_status = 1
+		ORDER BY s.board_id IS NOT NULL, s.position ASC, s.id ASC
+	`
+	return r.queryStatuses(query, workspaceID, boardID)
+}
+
 // GetStatusByExternalID retrieves a single status
 func (r *StatusRepository) GetStatusByExternalID(externalID string) (*models.Status, error) {
-	s := &models.Status{}
+	query := statusQuery + `
+		WHERE s.external_id = $1 AND s.active_status = 1
+	`
+	return scanStatus(r.DB.QueryRow(query, externalID))
+}
+
+// GetStatusForBoard retrieves a status only if tasks on the given board may use it
+func (r *StatusRepository) GetStatusForBoard(externalID string, boardID, workspaceID int) (*models.Status, error) {
+	query := statusQuery + `
+		WHERE s.external_id = $1 AND s.active_status = 1
+			AND s.workspace_id = $2 AND (s.board_id IS NULL OR s.board_id = $3)
+	`
+	return scanStatus(r.DB.QueryRow(query, externalID, workspaceID, boardID))
+}
+
+// IsStatusNameTaken checks whether another active status would share a column name on some board.
+// A workspace-wide status clashes with any status in the workspace, a board status with the
+// workspace-wide ones and those of its own board.
+func (r *StatusRepository) IsStatusNameTaken(workspaceID int, boardID *int, name string, excludeID int) (bool, error) {
+	var exists bool
 	query := `
-		SELECT id, external_id, name, color, position, active_status, created_at, modified_at
-		FROM statuses
-		WHERE external_id = $1 AND active_status = 1
+		SELECT EXISTS (
+			SELECT 1 FROM statuses
+			WHERE workspace_id = $1 AND LOWER(name) = LOWER($2) AND active_status = 1 AND id <> $3
+				AND ($4::INT IS NULL OR board_id IS NULL OR board_id = $4)
+		)
 	`
-	err := r.DB.QueryRow(query, externalID).Scan(
-		&s.ID,
-		&s.ExternalID,
-		&s.Name,
-		&s.Color,
-		&s.Position,
-		&s.ActiveStatus,
-		&s.CreatedAt,
-		&s.ModifiedAt,
+	err := r.DB.QueryRow(query, workspaceID, name, excludeID, boardID).Scan(&exists)
+	return exists, err
+}
+
+// UpdateStatus modifies an existing status and records the change against its previous values.
+// Moving the status into or out of the done category completes or reopens its tasks.
+func (r *StatusRepository) UpdateStatus(s *models.Status, previous *models.StatusSnapshot, actorID int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE statuses
+		SET name = $1, color = $2, category = $3, position = $4, modified_at = NOW(), modified_by = $5
+		WHERE id = $6
+		RETURNING modified_at
+	`
+	if err := tx.QueryRow(query, s.Name, s.Color, s.Category, s.Position, s.ModifiedBy, s.ID).Scan(&s.ModifiedAt); err != nil {
+		return err
+	}
+
+	if (previous.Category == models.StatusCategoryDone) != (s.Category == models.StatusCategoryDone) {
+		tasksQuery := `
+			UPDATE tasks
+			SET completed_at = CASE WHEN $1 THEN NOW() END
+			WHERE status_id = $2
+		`
+		if _, err := tx.Exec(tasksQuery, s.Category == models.StatusCategoryDone, s.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := recordStatusAudit(tx, s, models.StatusAuditUpdated, previous, snapshotStatus(s), &actorID, s.ModifiedBy); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteStatus soft deletes a status. With a target, every task using the status moves there first in the
+// same transaction, and the number moved is returned; without one, a status still in use is refused.
+func (r *StatusRepository) DeleteStatus(s *models.Status, target *models.Status, actorID int, deletedBy string) (int64, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Lock the status so no task can be moved into it while it is retired
+	lockQuery := `SELECT id FROM statuses WHERE id = $1 AND active_status = 1 FOR UPDATE`
+	if err := tx.QueryRow(lockQuery, s.ID).Scan(&s.ID); err != nil {
+		return 0, err
+	}
+
+	var migrated int64
+	if target != nil {
+		ids, ranks, err := appendRanksForMigration(tx, s.ID, target.ID)
+		if err != nil {
+			return 0, err
+		}
+
+		moveQuery := `
+			UPDATE tasks t
+			SET status_id = $1, rank = v.rank,
+				completed_at = CASE WHEN $2 THEN COALESCE(completed_at, NOW()) END,
+				modified_at = NOW(), modified_by = $3
+			FROM unnest($4::int[], $5::text[]) AS v (id, rank)
+			WHERE t.id = v.id
+		`
+		res, err := tx.Exec(moveQuery, target.ID, target.Category == models.StatusCategoryDone, deletedBy, pq.Array(ids), pq.Array(ranks))
+		if err != nil {
+			return 0, err
+		}
+		if migrated, err = res.RowsAffected(); err != nil {
+			return 0, err
+		}
+	} else {
+		var referenced bool
+		refQuery := `SELECT EXISTS (SELECT 1 FROM tasks WHERE status_id = $1)`
+		if err := tx.QueryRow(refQuery, s.ID).Scan(&referenced); err != nil {
+			return 0, err
+		}
+		if referenced {
+			return 0, ErrStatusInUse
+		}
+	}
+
+	deleteQuery := `
+		UPDATE statuses
+		SET active_status = 0, modified_at = NOW(), modified_by = $1
+		WHERE id = $2
+	`
+	if _, err := tx.Exec(deleteQuery, deletedBy, s.ID); err != nil {
+		return 0, err
+	}
+
+	if err := recordStatusAudit(tx, s, models.StatusAuditDeleted, snapshotStatus(s), nil, &actorID, &deletedBy); err != nil {
+		return 0, err
+	}
+
+	return migrated, tx.Commit()
+}
+
+// appendRanksForMigration lists the tasks of a retired status with new ranks that place them, in their
+// current order, below the tasks already in the target column of each board. Keeping their old keys
+// would collide with ranks in the target column. The affected boards are locked like any other drop.
+func appendRanksForMigration(tx *sql.Tx, fromStatusID, toStatusID int) ([]int64, []string, error) {
+	lockQuery := `
+		SELECT id FROM boards
+		WHERE id IN (SELECT board_id FROM tasks WHERE status_id = $1)
+		ORDER BY id
+		FOR UPDATE
+	`
+	if _, err := tx.Exec(lockQuery, fromStatusID); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := tx.Query(`SELECT id, board_id FROM tasks WHERE status_id = $1 ORDER BY board_id, rank, id`, fromStatusID)
+	if err != nil {
+		return nil, nil, err
+	}
+	type migratedTask struct {
+		id      int64
+		boardID int
+	}
+	var tasks []migratedTask
+	for rows.Next() {
+		var t migratedTask
+		if err := rows.Scan(&t.id, &t.boardID); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		ids   []int64
+		ranks []string
+		rank  string
 	)
+	for i, t := range tasks {
+		if i == 0 || t.boardID != tasks[i-1].boardID {
+			if rank, err = columnRankBefore(tx, t.boardID, toStatusID, 0, ""); err != nil {
+				return nil, nil, err
+			}
+		}
+		rank = utils.RankAfter(rank)
+		ids = append(ids, t.id)
+		ranks = append(ranks, rank)
+	}
+	return ids, ranks, nil
+}
+
+// ReorderStatuses renumbers the positions of a scope's statuses, the workspace-wide ones when boardID is nil
+// or one board's own, to follow externalIDs. The list must name every active status of the scope exactly once.
+func (r *StatusRepository) ReorderStatuses(workspaceID int, boardID *int, externalIDs []string, actorID int, modifiedBy string) ([]*models.Status, error) {
+	tx, err := r.DB.Begin()
 	if err != nil {
 		return nil, err
 	}
-	return s, nil
-}
+	defer tx.Rollback()
 
-// GetStatusByName checks if a status with the same name already exists
-func (r *StatusRepository) GetStatusByName(name string) (*models.Status, error) {
-	s := &models.Status{}
-	query := `
-		SELECT id, external_id, name
-		FROM statuses
-		WHERE name = $1 AND active_status = 1
+	// Lock the scope so concurrent creates, updates or reorders cannot interleave
+	query := statusQuery + `
+		WHERE s.workspace_id = $1 AND s.board_id IS NOT DISTINCT FROM $2 AND s.active_status = 1
+		FOR UPDATE OF s
 	`
-	err := r.DB.QueryRow(query, name).Scan(&s.ID, &s.ExternalID, &s.Name)
+	rows, err := tx.Query(query, workspaceID, boardID)
 	if err != nil {
 		return nil, err
 	}
-	return s, nil
-}
+	current := make(map[string]*models.Status)
+	for rows.Next() {
+		st, err := scanStatus(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		current[st.ExternalID] = st
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-// UpdateStatus modifies an existing status
-func (r *StatusRepository) UpdateStatus(s *models.Status) error {
-	query := `
+	if len(externalIDs) != len(current) {
+		return nil, ErrStatusOrderMismatch
+	}
+
+	updateQuery := `
 		UPDATE statuses
-		SET name = $1, color = $2, position = $3, modified_at = NOW()
-		WHERE id = $4
+		SET position = $1, modified_at = NOW(), modified_by = $2
+		WHERE id = $3
 		RETURNING modified_at
 	`
-	return r.DB.QueryRow(query, s.Name, s.Color, s.Position, s.ID).Scan(&s.ModifiedAt)
+	statuses := make([]*models.Status, 0, len(externalIDs))
+	for i, extID := range externalIDs {
+		st, ok := current[extID]
+		if !ok {
+			return nil, ErrStatusOrderMismatch
+		}
+		delete(current, extID)
+		statuses = append(statuses, st)
+
+		if st.Position == i {
+			continue
+		}
+		previous := snapshotStatus(st)
+		st.Position = i
+		st.ModifiedBy = &modifiedBy
+		if err := tx.QueryRow(updateQuery, st.Position, modifiedBy, st.ID).Scan(&st.ModifiedAt); err != nil {
+			return nil, err
+		}
+		if err := recordStatusAudit(tx, st, models.StatusAuditUpdated, previous, snapshotStatus(st), &actorID, &modifiedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return statuses, nil
 }
 
-// CheckIfReferenced checks if the status is being used by any active tasks
-func (r *StatusRepository) CheckIfReferenced(statusID int) (bool, error) {
-	var count int
+// GetAuditLogsByWorkspaceID lists the status changes of a workspace, newest first
+func (r *StatusRepository) GetAuditLogsByWorkspaceID(workspaceID int) ([]*models.StatusAuditLog, error) {
 	query := `
-		SELECT COUNT(*)
-		FROM tasks
-		WHERE status_id = $1 AND active_status = 1
+		SELECT
+			l.id, l.status_id, l.status_external_id, l.workspace_id, l.board_id, b.external_id, l.action,
+			l.old_values, l.new_values, l.actor_id, u.external_id, u.name, l.created_at, l.created_by
+		FROM status_audit_logs l
+		LEFT JOIN boards b ON l.board_id = b.id
+		LEFT JOIN users u ON l.actor_id = u.id
+		WHERE l.workspace_id = $1
+		ORDER BY l.created_at DESC, l.id DESC
 	`
-	err := r.DB.QueryRow(query, statusID).Scan(&count)
+	rows, err := r.DB.Query(query, workspaceID)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	return count > 0, nil
+	defer rows.Close()
+
+	var logs []*models.StatusAuditLog
+	for rows.Next() {
+		l := &models.StatusAuditLog{}
+		var oldValues, newValues []byte
+		err := rows.Scan(
+			&l.ID,
+			&l.StatusID,
+			&l.StatusExternalID,
+			&l.WorkspaceID,
+			&l.BoardID,
+			&l.BoardExternalID,
+			&l.Action,
+			&oldValues,
+			&newValues,
+			&l.ActorID,
+			&l.ActorExternalID,
+			&l.ActorName,
+			&l.CreatedAt,
+			&l.CreatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		l.OldValues = oldValues
+		l.NewValues = newValues
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
+
+// snapshotStatus captures the audited fields of a status
+func snapshotStatus(s *models.Status) *models.StatusSnapshot {
+	return &models.StatusSnapshot{Name: s.Name, Color: s.Color, Category: s.Category, Position: s.Position}
 }
 
-// DeleteStatus performs a hard delete or soft delete
-func (r *StatusRepository) DeleteStatus(id int) error {
-	query := `DELETE FROM statuses WHERE id = $1`
-	_, err := r.DB.Exec(query, id)
+// recordStatusAudit appends an audit entry within the transaction that changes the status
+func recordStatusAudit(tx *sql.Tx, s *models.Status, action string, oldValues, newValues *models.StatusSnapshot, actorID *int, actorExternalID *string) error {
+	oldJSON, err := marshalSnapshot(oldValues)
+	if err != nil {
+		return err
+	}
+	newJSON, err := marshalSnapshot(newValues)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO status_audit_logs (status_id, status_external_id, workspace_id, board_id, action, old_values, new_values, actor_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = tx.Exec(query, s.ID, s.ExternalID, s.WorkspaceID, s.BoardID, action, oldJSON, newJSON, actorID, actorExternalID)
 	return err
 }
+
+// marshalSnapshot encodes a snapshot for a JSONB column, keeping a missing one NULL
+func marshalSnapshot(snapshot *models.StatusSnapshot) (interface{}, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
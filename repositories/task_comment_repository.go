@@ -0,0 +1,218 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/lib/pq"
+)
+
+type TaskCommentRepository struct {
+	DB *sql.DB
+}
+
+func NewTaskCommentRepository(db *sql.DB) *TaskCommentRepository {
+	return &TaskCommentRepository{DB: db}
+}
+
+// commentQuery selects a comment joined with its author
+const commentQuery = `
+	SELECT
+		c.id, c.external_id, c.task_id, c.author_id, u.external_id, u.name, c.body, c.edited_at,
+		c.active_status, c.created_at, c.created_by, c.modified_at, c.modified_by
+	FROM task_comments c
+	LEFT JOIN users u ON c.author_id = u.id
+`
+
+// scanComment maps a row selected with commentQuery into a TaskComment
+func scanComment(row rowScanner) (*models.TaskComment, error) {
+	c := &models.TaskComment{}
+	err := row.Scan(
+		&c.ID,
+		&c.ExternalID,
+		&c.TaskID,
+		&c.AuthorID,
+		&c.AuthorExternalID,
+		&c.AuthorName,
+		&c.Body,
+		&c.EditedAt,
+		&c.ActiveStatus,
+		&c.CreatedAt,
+		&c.CreatedBy,
+		&c.ModifiedAt,
+		&c.ModifiedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CreateComment stores a comment together with its mentions
+func (r *TaskCommentRepository) CreateComment(c *models.TaskComment) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO task_comments (external_id, task_id, author_id, body, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, active_status, created_at
+	`
+	err = tx.QueryRow(query, c.ExternalID, c.TaskID, c.AuthorID, c.Body, c.CreatedBy).
+		Scan(&c.ID, &c.ActiveStatus, &c.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertMentions(tx, c); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetCommentsByTaskID returns one page of a task's active comments, newest first, with the total count
+func (r *TaskCommentRepository) GetCommentsByTaskID(taskID, limit, offset int) ([]*models.TaskComment, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM task_comments WHERE task_id = $1 AND active_status = 1`
+	if err := r.DB.QueryRow(countQuery, taskID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := commentQuery + `
+		WHERE c.task_id = $1 AND c.active_status = 1
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.DB.Query(query, taskID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var comments []*models.TaskComment
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		comments = append(comments, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.loadMentions(comments); err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// GetCommentByExternalID retrieves an active comment of a task
+func (r *TaskCommentRepository) GetCommentByExternalID(taskID int, externalID string) (*models.TaskComment, error) {
+	query := commentQuery + `
+		WHERE c.task_id = $1 AND c.external_id = $2 AND c.active_status = 1
+	`
+	c, err := scanComment(r.DB.QueryRow(query, taskID, externalID))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadMentions([]*models.TaskComment{c}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UpdateComment replaces a comment's body and mentions and marks it edited
+func (r *TaskCommentRepository) UpdateComment(c *models.TaskComment) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE task_comments
+		SET body = $1, edited_at = NOW(), modified_at = NOW(), modified_by = $2
+		WHERE id = $3 AND active_status = 1
+		RETURNING edited_at, modified_at
+	`
+	if err := tx.QueryRow(query, c.Body, c.ModifiedBy, c.ID).Scan(&c.EditedAt, &c.ModifiedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM task_comment_mentions WHERE comment_id = $1`, c.ID); err != nil {
+		return err
+	}
+	if err := insertMentions(tx, c); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteComment soft deletes a comment
+func (r *TaskCommentRepository) DeleteComment(c *models.TaskComment) error {
+	query := `
+		UPDATE task_comments
+		SET active_status = 0, modified_at = NOW(), modified_by = $1
+		WHERE id = $2
+	`
+	_, err := r.DB.Exec(query, c.ModifiedBy, c.ID)
+	return err
+}
+
+// insertMentions stores the mentions of a comment, resolving users by external ID
+func insertMentions(tx *sql.Tx, c *models.TaskComment) error {
+	query := `
+		INSERT INTO task_comment_mentions (comment_id, user_id, mention_text)
+		SELECT $1, id, $3 FROM users WHERE external_id = $2
+	`
+	for _, m := range c.Mentions {
+		if _, err := tx.Exec(query, c.ID, m.UserExternalID, m.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadMentions fills in the mentions of the given comments with one query
+func (r *TaskCommentRepository) loadMentions(comments []*models.TaskComment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.TaskComment, len(comments))
+	ids := make([]int64, 0, len(comments))
+	for _, c := range comments {
+		byID[c.ID] = c
+		c.Mentions = []*models.CommentMention{}
+		ids = append(ids, int64(c.ID))
+	}
+
+	query := `
+		SELECT m.comment_id, u.external_id, u.name, m.mention_text
+		FROM task_comment_mentions m
+		JOIN users u ON m.user_id = u.id
+		WHERE m.comment_id = ANY($1)
+		ORDER BY m.id ASC
+	`
+	rows, err := r.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentID int
+		m := &models.CommentMention{}
+		if err := rows.Scan(&commentID, &m.UserExternalID, &m.Name, &m.Text); err != nil {
+			return err
+		}
+		byID[commentID].Mentions = append(byID[commentID].Mentions, m)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+// ErrDependencyCycle is returned when a new link would let a task end up blocking itself
+var ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+// ErrDependencyExists is returned when the two tasks are already linked in that direction
+var ErrDependencyExists = errors.New("dependency already exists")
+
+type TaskDependencyRepository struct {
+	DB *sql.DB
+}
+
+func NewTaskDependencyRepository(db *sql.DB) *TaskDependencyRepository {
+	return &TaskDependencyRepository{DB: db}
+}
+
+// AddDependency links a blocker to a blocked task. The workspace's task graph is locked and searched
+// from the blocked task: if the blocker is already downstream of it, the link would close a loop.
+func (r *TaskDependencyRepository) AddDependency(d *models.TaskDependency, boardID int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockTaskGraph(tx, boardID); err != nil {
+		return err
+	}
+
+	cycleQuery := `
+		WITH RECURSIVE downstream AS (
+			SELECT blocked_task_id AS id FROM task_dependencies WHERE blocker_task_id = $1
+			UNION
+			SELECT td.blocked_task_id FROM task_dependencies td JOIN downstream ds ON td.blocker_task_id = ds.id
+		)
+		SELECT EXISTS (SELECT 1 FROM downstream WHERE id = $2)
+	`
+	var cycle bool
+	if err := tx.QueryRow(cycleQuery, d.BlockedTaskID, d.BlockerTaskID).Scan(&cycle); err != nil {
+		return err
+	}
+	if cycle {
+		return ErrDependencyCycle
+	}
+
+	query := `
+		INSERT INTO task_dependencies (blocker_task_id, blocked_task_id, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRow(query, d.BlockerTaskID, d.BlockedTaskID, d.CreatedBy).Scan(&d.ID, &d.CreatedAt); err != nil {
+		if IsUniqueViolation(err) {
+			return ErrDependencyExists
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RemoveDependency deletes a link, returning sql.ErrNoRows when there was none
+func (r *TaskDependencyRepository) RemoveDependency(blockerTaskID, blockedTaskID int) error {
+	res, err := r.DB.Exec(`DELETE FROM task_dependencies WHERE blocker_task_id = $1 AND blocked_task_id = $2`, blockerTaskID, blockedTaskID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetBlockers lists the tasks blocking a task
+func (r *TaskDependencyRepository) GetBlockers(taskID int) ([]*models.DependencyTaskInfo, error) {
+	return r.queryDependencyTasks(`
+		SELECT t.external_id, b.external_id, t.title, s.external_id, s.name, s.color, s.category, t.completed_at, td.created_at
+		FROM task_dependencies td
+		JOIN tasks t ON t.id = td.blocker_task_id
+		JOIN boards b ON t.board_id = b.id
+		JOIN statuses s ON t.status_id = s.id
+		WHERE td.blocked_task_id = $1 AND t.active_status = 1
+		ORDER BY td.created_at ASC, td.id ASC
+	`, taskID)
+}
+
+// GetDependents lists the tasks a task blocks
+func (r *TaskDependencyRepository) GetDependents(taskID int) ([]*models.DependencyTaskInfo, error) {
+	return r.queryDependencyTasks(`
+		SELECT t.external_id, b.external_id, t.title, s.external_id, s.name, s.color, s.category, t.completed_at, td.created_at
+		FROM task_dependencies td
+		JOIN tasks t ON t.id = td.blocked_task_id
+		JOIN boards b ON t.board_id = b.id
+		JOIN statuses s ON t.status_id = s.id
+		WHERE td.blocker_task_id = $1 AND t.active_status = 1
+		ORDER BY td.created_at ASC, td.id ASC
+	`, taskID)
+}
+
+func (r *TaskDependencyRepository) queryDependencyTasks(query string, taskID int) ([]*models.DependencyTaskInfo, error) {
+	rows, err := r.DB.Query(query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.DependencyTaskInfo{}
+	for rows.Next() {
+		t := &models.DependencyTaskInfo{}
+		if err := rows.Scan(
+			&t.ExternalID,
+			&t.BoardExternalID,
+			&t.Title,
+			&t.Status.ExternalID,
+			&t.Status.Name,
+			&t.Status.Color,
+			&t.Status.Category,
+			&t.CompletedAt,
+			&t.LinkedAt,
+		); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// CountOpenBlockers counts the blockers of a task that are not completed yet
+func (r *TaskDependencyRepository) CountOpenBlockers(taskID int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM task_dependencies td
+		JOIN tasks t ON t.id = td.blocker_task_id
+		WHERE td.blocked_task_id = $1 AND t.completed_at IS NULL AND t.active_status = 1
+	`
+	var count int
+	err := r.DB.QueryRow(query, taskID).Scan(&count)
+	return count, err
+}
@@ -2,8 +2,13 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 
 	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/utils"
+	"github.com/lib/pq"
 )
 
 type TaskRepository struct {
@@ -14,18 +19,47 @@ func NewTaskRepository(db *sql.DB) *TaskRepository {
 	return &TaskRepository{DB: db}
 }
 
-// CreateTask adds a new task to a board
+// ErrInvalidNeighbor is returned when a move names a neighbor outside the target column, or neighbors out of order
+var ErrInvalidNeighbor = errors.New("neighbor task is not in the target column")
+
+// ErrTaskCycle is returned when a new parent is the task itself or one of its descendants
+var ErrTaskCycle = errors.New("parent task would create a cycle")
+
+// ErrTaskHasSubtasks is returned when the block policy stops a task with children from being deleted
+var ErrTaskHasSubtasks = errors.New("task has subtasks")
+
+// CreateTask adds a new task to the bottom of its status column; a task created straight into a done status is completed
 func (r *TaskRepository) CreateTask(task *models.Task) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockBoard(tx, task.BoardID); err != nil {
+		return err
+	}
+
+	task.Rank, err = rankInColumn(tx, task.BoardID, task.StatusID, func() (string, string, error) {
+		lower, err := columnRankBefore(tx, task.BoardID, task.StatusID, 0, "")
+		return lower, "", err
+	})
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO tasks (external_id, board_id, status_id, assigned_to, created_by_id, title, description, priority, due_date, position)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id, created_at
+		INSERT INTO tasks (external_id, board_id, status_id, parent_task_id, assigned_to, created_by_id, title, description, priority, due_date, position, rank, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+			CASE WHEN (SELECT category FROM statuses WHERE id = $3) = 'done' THEN NOW() END)
+		RETURNING id, completed_at, created_at
 	`
-	return r.DB.QueryRow(
+	err = tx.QueryRow(
 		query,
 		task.ExternalID,
 		task.BoardID,
 		task.StatusID,
+		task.ParentTaskID,
 		task.AssignedTo,
 		task.CreatedByID,
 		task.Title,
@@ -33,35 +67,157 @@ func (r *TaskRepository) CreateTask(task *models.Task) error {
 		task.Priority,
 		task.DueDate,
 		task.Position,
-	).Scan(&task.ID, &task.CreatedAt)
+		task.Rank,
+	).Scan(&task.ID, &task.CompletedAt, &task.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// GetTasksByBoardID gets all active tasks for a specific board
-func (r *TaskRepository) GetTasksByBoardID(boardID int) ([]*models.TaskResponse, error) {
-	query := `
-		SELECT 
-			t.external_id,
-			b.external_id AS board_external_id,
-			s.external_id AS status_external_id,
-			s.name AS status_name,
-			s.color AS status_color,
-			u.external_id AS assignee_external_id,
-			u.name AS assignee_name,
-			t.title,
-			t.description,
-			t.priority,
-			t.due_date,
-			t.position,
-			t.created_at,
-			t.modified_at
-		FROM tasks t
-		JOIN boards b ON t.board_id = b.id
-		JOIN statuses s ON t.status_id = s.id
-		LEFT JOIN users u ON t.assigned_to = u.id
+// taskResponseQuery selects a task joined with its board, parent, status, assignee, creator, checklist progress
+// and labels, flagging tasks that still have an open blocker. Labels are aggregated to JSON in the same row, so
+// listing a board stays a single query.
+const taskResponseQuery = `
+	SELECT 
+		t.external_id,
+		b.external_id AS board_external_id,
+		p.external_id AS parent_task_external_id,
+		s.external_id AS status_external_id,
+		s.name AS status_name,
+		s.color AS status_color,
+		s.category AS status_category,
+		u.external_id AS assignee_external_id,
+		u.name AS assignee_name,
+		c.external_id AS creator_external_id,
+		c.name AS creator_name,
+		t.title,
+		t.description,
+		t.priority,
+		t.due_date,
+		t.position,
+		t.rank,
+		t.completed_at,
+		cl.done AS checklist_done,
+		cl.total AS checklist_total,
+		EXISTS (
+			SELECT 1
+			FROM task_dependencies td
+			JOIN tasks bt ON td.blocker_task_id = bt.id
+			WHERE td.blocked_task_id = t.id AND bt.completed_at IS NULL AND bt.active_status = 1
+		) AS is_blocked,
+		COALESCE((
+			SELECT json_agg(json_build_object('external_id', l.external_id, 'name', l.name, 'color', l.color) ORDER BY LOWER(l.name), l.id)
+			FROM task_labels tl
+			JOIN labels l ON tl.label_id = l.id
+			WHERE tl.task_id = t.id
+		), '[]') AS labels,
+		t.created_at,
+		t.modified_at
+	FROM tasks t
+	JOIN boards b ON t.board_id = b.id
+	JOIN statuses s ON t.status_id = s.id
+	LEFT JOIN tasks p ON t.parent_task_id = p.id
+	LEFT JOIN users u ON t.assigned_to = u.id
+	LEFT JOIN users c ON t.created_by_id = c.id
+	CROSS JOIN LATERAL (
+		SELECT COUNT(*) FILTER (WHERE ci.is_done) AS done, COUNT(*) AS total
+		FROM task_checklist_items ci
+		WHERE ci.task_id = t.id
+	) cl
+`
+
+// scanTaskResponse maps a row selected with taskResponseQuery into a TaskResponse
+func scanTaskResponse(row rowScanner) (*models.TaskResponse, error) {
+	var (
+		assigneeExtID *string
+		assigneeName  *string
+		creatorExtID  *string
+		creatorName   *string
+		statusColor   *string
+		labels        []byte
+	)
+	tr := &models.TaskResponse{}
+
+	if err := row.Scan(
+		&tr.ExternalID,
+		&tr.BoardExternalID,
+		&tr.ParentTaskExternalID,
+		&tr.Status.ExternalID,
+		&tr.Status.Name,
+		&statusColor,
+		&tr.Status.Category,
+		&assigneeExtID,
+		&assigneeName,
+		&creatorExtID,
+		&creatorName,
+		&tr.Title,
+		&tr.Description,
+		&tr.Priority,
+		&tr.DueDate,
+		&tr.Position,
+		&tr.Rank,
+		&tr.CompletedAt,
+		&tr.Checklist.Done,
+		&tr.Checklist.Total,
+		&tr.IsBlocked,
+		&labels,
+		&tr.CreatedAt,
+		&tr.ModifiedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	tr.Status.Color = statusColor
+
+	if err := json.Unmarshal(labels, &tr.Labels); err != nil {
+		return nil, err
+	}
+
+	if assigneeExtID != nil {
+		tr.AssignedTo = &models.TaskUserInfo{
+			ExternalID: *assigneeExtID,
+			Name:       *assigneeName,
+		}
+	}
+
+	if creatorExtID != nil {
+		tr.CreatedBy = &models.TaskUserInfo{
+			ExternalID: *creatorExtID,
+			Name:       *creatorName,
+		}
+	}
+
+	return tr, nil
+}
+
+// GetTasksByBoardID gets all active tasks for a specific board, optionally only open or completed ones,
+// and optionally only those carrying any or all of the labels in the filter
+func (r *TaskRepository) GetTasksByBoardID(boardID int, state string, labels *models.TaskLabelFilter) ([]*models.TaskResponse, error) {
+	query := taskResponseQuery + `
 		WHERE t.board_id = $1 AND t.active_status = 1
-		ORDER BY s.position ASC, t.position ASC
 	`
-	rows, err := r.DB.Query(query, boardID)
+	args := []interface{}{boardID}
+	switch state {
+	case models.TaskStateOpen:
+		query += ` AND t.completed_at IS NULL`
+	case models.TaskStateCompleted:
+		query += ` AND t.completed_at IS NOT NULL`
+	}
+	if labels != nil && len(labels.LabelIDs) > 0 {
+		args = append(args, pq.Array(labels.LabelIDs))
+		if labels.MatchAll {
+			args = append(args, len(labels.LabelIDs))
+			query += fmt.Sprintf(` AND (SELECT COUNT(*) FROM task_labels tl WHERE tl.task_id = t.id AND tl.label_id = ANY($%d)) = $%d`, len(args)-1, len(args))
+		} else {
+			query += fmt.Sprintf(` AND EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = t.id AND tl.label_id = ANY($%d))`, len(args))
+		}
+	}
+	query += `
+		ORDER BY s.board_id IS NOT NULL, s.position ASC, t.rank ASC, t.id ASC
+	`
+	rows, err := r.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -69,51 +225,28 @@ func (r *TaskRepository) GetTasksByBoardID(boardID int) ([]*models.TaskResponse,
 
 	var tasks []*models.TaskResponse
 	for rows.Next() {
-		var (
-			assigneeExtID *string
-			assigneeName  *string
-			statusColor   *string
-		)
-		tr := &models.TaskResponse{}
-
-		if err := rows.Scan(
-			&tr.ExternalID,
-			&tr.BoardExternalID,
-			&tr.Status.ExternalID,
-			&tr.Status.Name,
-			&statusColor,
-			&assigneeExtID,
-			&assigneeName,
-			&tr.Title,
-			&tr.Description,
-			&tr.Priority,
-			&tr.DueDate,
-			&tr.Position,
-			&tr.CreatedAt,
-			&tr.ModifiedAt,
-		); err != nil {
+		tr, err := scanTaskResponse(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		tr.Status.Color = statusColor
-
-		if assigneeExtID != nil {
-			tr.AssignedTo = &models.TaskAssigneeInfo{
-				ExternalID: *assigneeExtID,
-				Name:       *assigneeName,
-			}
-		}
-
 		tasks = append(tasks, tr)
 	}
 
 	return tasks, nil
 }
 
+// GetTaskResponseByExternalID retrieves a single fully joined task view
+func (r *TaskRepository) GetTaskResponseByExternalID(externalID string) (*models.TaskResponse, error) {
+	query := taskResponseQuery + `
+		WHERE t.external_id = $1 AND t.active_status = 1
+	`
+	return scanTaskResponse(r.DB.QueryRow(query, externalID))
+}
+
 // GetTaskByExternalID retrieves details of a specific task
 func (r *TaskRepository) GetTaskByExternalID(externalID string) (*models.Task, error) {
 	query := `
-		SELECT id, external_id, board_id, status_id, assigned_to, created_by_id, title, description, priority, due_date, position, active_status, created_at, modified_at
+		SELECT id, external_id, board_id, status_id, parent_task_id, assigned_to, created_by_id, title, description, priority, due_date, position, rank, completed_at, active_status, created_at, modified_at
 		FROM tasks
 		WHERE external_id = $1 AND active_status = 1
 	`
@@ -123,6 +256,7 @@ func (r *TaskRepository) GetTaskByExternalID(externalID string) (*models.Task, e
 		&t.ExternalID,
 		&t.BoardID,
 		&t.StatusID,
+		&t.ParentTaskID,
 		&t.AssignedTo,
 		&t.CreatedByID,
 		&t.Title,
@@ -130,6 +264,8 @@ func (r *TaskRepository) GetTaskByExternalID(externalID string) (*models.Task, e
 		&t.Priority,
 		&t.DueDate,
 		&t.Position,
+		&t.Rank,
+		&t.CompletedAt,
 		&t.ActiveStatus,
 		&t.CreatedAt,
 		&t.ModifiedAt,
@@ -140,21 +276,394 @@ func (r *TaskRepository) GetTaskByExternalID(externalID string) (*models.Task, e
 	return t, nil
 }
 
-// UpdateTask modifies a task
+// UpdateTask modifies a task. A task changing status goes to the bottom of its new column.
+// Entering a done status sets completed_at, leaving the done category clears it;
+// moving between done statuses keeps the original completion time.
+// A new parent is refused with ErrTaskCycle when it sits below the task in the hierarchy.
 func (r *TaskRepository) UpdateTask(t *models.Task) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the board as MoveTask does, so the status read below stays current until the update
+	if err := lockBoard(tx, t.BoardID); err != nil {
+		return err
+	}
+
+	var (
+		currentStatusID int
+		currentParentID *int
+	)
+	err = tx.QueryRow(`SELECT status_id, parent_task_id FROM tasks WHERE id = $1`, t.ID).Scan(&currentStatusID, &currentParentID)
+	if err != nil {
+		return err
+	}
+
+	if t.ParentTaskID != nil && (currentParentID == nil || *currentParentID != *t.ParentTaskID) {
+		if err := checkParentCycle(tx, t); err != nil {
+			return err
+		}
+	}
+
+	// The rank only changes when the task moves to another column; otherwise the stored rank is
+	// kept, so a stale copy in t cannot undo a concurrent MoveTask
+	var rank *string
+	if currentStatusID != t.StatusID {
+		newRank, err := rankInColumn(tx, t.BoardID, t.StatusID, func() (string, string, error) {
+			lower, err := columnRankBefore(tx, t.BoardID, t.StatusID, t.ID, "")
+			return lower, "", err
+		})
+		if err != nil {
+			return err
+		}
+		rank = &newRank
+	}
+
 	query := `
 		UPDATE tasks
-		SET title = $1, description = $2, priority = $3, due_date = $4, status_id = $5, assigned_to = $6, position = $7, modified_at = NOW()
-		WHERE id = $8
-		RETURNING modified_at
+		SET title = $1, description = $2, priority = $3, due_date = $4, status_id = $5, assigned_to = $6, position = $7, rank = COALESCE($8, rank),
+			completed_at = CASE WHEN (SELECT category FROM statuses WHERE id = $5) = 'done' THEN COALESCE(completed_at, NOW()) END,
+			parent_task_id = $9,
+			modified_at = NOW(), modified_by = $10
+		WHERE id = $11
+		RETURNING rank, completed_at, modified_at
+	`
+	err = tx.QueryRow(query, t.Title, t.Description, t.Priority, t.DueDate, t.StatusID, t.AssignedTo, t.Position, rank, t.ParentTaskID, t.ModifiedBy, t.ID).
+		Scan(&t.Rank, &t.CompletedAt, &t.ModifiedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MoveTask places a task in a status column right below afterExternalID and/or above beforeExternalID,
+// or at the bottom when neither is given. The board row is locked so concurrent drops on the same
+// board are applied one after another and never compute the same key.
+func (r *TaskRepository) MoveTask(t *models.Task, statusID int, afterExternalID, beforeExternalID *string, movedBy string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockBoard(tx, t.BoardID); err != nil {
+		return err
+	}
+
+	rank, err := rankInColumn(tx, t.BoardID, statusID, func() (lower, upper string, err error) {
+		if afterExternalID != nil {
+			if lower, err = neighborRank(tx, t, statusID, *afterExternalID); err != nil {
+				return "", "", err
+			}
+		}
+		if beforeExternalID != nil {
+			if upper, err = neighborRank(tx, t, statusID, *beforeExternalID); err != nil {
+				return "", "", err
+			}
+		}
+
+		switch {
+		case afterExternalID != nil && beforeExternalID != nil:
+			if lower >= upper {
+				return "", "", ErrInvalidNeighbor
+			}
+		case afterExternalID != nil:
+			upper, err = columnRankAfter(tx, t.BoardID, statusID, t.ID, lower)
+		default:
+			// Below the task's upper neighbor, or the last task of the column when no neighbor was given
+			lower, err = columnRankBefore(tx, t.BoardID, statusID, t.ID, upper)
+		}
+		return lower, upper, err
+	})
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE tasks
+		SET status_id = $1, rank = $2,
+			completed_at = CASE WHEN (SELECT category FROM statuses WHERE id = $1) = 'done' THEN COALESCE(completed_at, NOW()) END,
+			modified_at = NOW(), modified_by = $3
+		WHERE id = $4
+		RETURNING rank, completed_at, modified_at
+	`
+	err = tx.QueryRow(query, statusID, rank, movedBy, t.ID).
+		Scan(&t.Rank, &t.CompletedAt, &t.ModifiedAt)
+	if err != nil {
+		return err
+	}
+	t.StatusID = statusID
+
+	return tx.Commit()
+}
+
+// lockBoard serializes rank changes on a board for the rest of the transaction
+func lockBoard(tx *sql.Tx, boardID int) error {
+	var id int
+	return tx.QueryRow(`SELECT id FROM boards WHERE id = $1 FOR UPDATE`, boardID).Scan(&id)
+}
+
+// rankInColumn computes a key between the bounds read by bounds. When the key would grow past
+// utils.RankRebalanceLength, the column is re-ranked first and the bounds are read again.
+func rankInColumn(tx *sql.Tx, boardID, statusID int, bounds func() (string, string, error)) (string, error) {
+	lower, upper, err := bounds()
+	if err != nil {
+		return "", err
+	}
+	rank := utils.RankBetween(lower, upper)
+	if len(rank) <= utils.RankRebalanceLength {
+		return rank, nil
+	}
+
+	if err := rebalanceColumn(tx, boardID, statusID); err != nil {
+		return "", err
+	}
+	if lower, upper, err = bounds(); err != nil {
+		return "", err
+	}
+	return utils.RankBetween(lower, upper), nil
+}
+
+// rebalanceColumn replaces the ranks of a status column on a board with evenly spaced short keys,
+// keeping the current order. The caller must hold the board lock.
+func rebalanceColumn(tx *sql.Tx, boardID, statusID int) error {
+	rows, err := tx.Query(`SELECT id FROM tasks WHERE board_id = $1 AND status_id = $2 ORDER BY rank, id`, boardID, statusID)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return updateRanks(tx, ids, utils.RankSequence(len(ids)))
+}
+
+// updateRanks writes new ranks to tasks in a single statement
+func updateRanks(tx *sql.Tx, ids []int64, ranks []string) error {
+	query := `
+		UPDATE tasks t
+		SET rank = v.rank
+		FROM unnest($1::int[], $2::text[]) AS v (id, rank)
+		WHERE t.id = v.id
+	`
+	_, err := tx.Exec(query, pq.Array(ids), pq.Array(ranks))
+	return err
+}
+
+// neighborRank reads the rank of a task the moving task is dropped next to; it must already be in the target column
+func neighborRank(tx *sql.Tx, t *models.Task, statusID int, neighborExternalID string) (string, error) {
+	var neighborID, neighborStatusID int
+	var rank string
+	query := `
+		SELECT id, status_id, rank
+		FROM tasks
+		WHERE external_id = $1 AND board_id = $2 AND active_status = 1
 	`
-	return r.DB.QueryRow(query, t.Title, t.Description, t.Priority, t.DueDate, t.StatusID, t.AssignedTo, t.Position, t.ID).
-		Scan(&t.ModifiedAt)
+	err := tx.QueryRow(query, neighborExternalID, t.BoardID).Scan(&neighborID, &neighborStatusID, &rank)
+	if err == sql.ErrNoRows || (err == nil && (neighborID == t.ID || neighborStatusID != statusID)) {
+		return "", ErrInvalidNeighbor
+	}
+	return rank, err
+}
+
+// columnRankBefore returns the highest rank in a column below upper (or overall when upper is empty),
+// ignoring the moving task; empty when there is none
+func columnRankBefore(tx *sql.Tx, boardID, statusID, excludeID int, upper string) (string, error) {
+	var rank sql.NullString
+	query := `
+		SELECT MAX(rank)
+		FROM tasks
+		WHERE board_id = $1 AND status_id = $2 AND id <> $3 AND active_status = 1 AND ($4 = '' OR rank < $4)
+	`
+	err := tx.QueryRow(query, boardID, statusID, excludeID, upper).Scan(&rank)
+	return rank.String, err
+}
+
+// columnRankAfter returns the lowest rank in a column above lower, ignoring the moving task; empty when there is none
+func columnRankAfter(tx *sql.Tx, boardID, statusID, excludeID int, lower string) (string, error) {
+	var rank sql.NullString
+	query := `
+		SELECT MIN(rank)
+		FROM tasks
+		WHERE board_id = $1 AND status_id = $2 AND id <> $3 AND active_status = 1 AND rank > $4
+	`
+	err := tx.QueryRow(query, boardID, statusID, excludeID, lower).Scan(&rank)
+	return rank.String, err
+}
+
+// subtreeQuery selects the ids of task $1 and every task below it
+const subtreeQuery = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM tasks WHERE id = $1
+		UNION
+		SELECT t.id FROM tasks t JOIN subtree st ON t.parent_task_id = st.id
+	)
+	SELECT id FROM subtree
+`
+
+// DeleteTask removes a task and returns the storage keys of its attachments for blob cleanup.
+// subtaskPolicy decides what happens to its children: cascade deletes the whole subtree,
+// orphan leaves them as top-level tasks and block refuses with ErrTaskHasSubtasks.
+func (r *TaskRepository) DeleteTask(id int, subtaskPolicy string) ([]string, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT 1 FROM tasks WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return nil, err
+	}
+
+	target := `SELECT $1::INT`
+	switch subtaskPolicy {
+	case models.SubtaskDeleteCascade:
+		target = subtreeQuery
+		if _, err := tx.Exec(`SELECT 1 FROM tasks WHERE id IN (`+target+`) FOR UPDATE`, id); err != nil {
+			return nil, err
+		}
+	case models.SubtaskDeleteBlock:
+		var hasChildren bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM tasks WHERE parent_task_id = $1)`, id).Scan(&hasChildren); err != nil {
+			return nil, err
+		}
+		if hasChildren {
+			return nil, ErrTaskHasSubtasks
+		}
+	}
+
+	keys, err := deleteAttachmentsReturningKeys(tx, target, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Orphaned children are detached by the ON DELETE SET NULL foreign key
+	if _, err := tx.Exec(`DELETE FROM tasks WHERE id IN (`+target+`)`, id); err != nil {
+		return nil, err
+	}
+
+	return keys, tx.Commit()
 }
 
-// DeleteTask removes a task
-func (r *TaskRepository) DeleteTask(id int) error {
-	query := `DELETE FROM tasks WHERE id = $1`
-	_, err := r.DB.Exec(query, id)
+// lockTaskGraph locks the workspace of a board so that concurrent changes to the links between its
+// tasks (parents, dependencies) cannot each pass a cycle check and together form a loop
+func lockTaskGraph(tx *sql.Tx, boardID int) error {
+	query := `
+		SELECT w.id FROM workspaces w
+		JOIN boards b ON b.workspace_id = w.id
+		WHERE b.id = $1
+		FOR NO KEY UPDATE OF w
+	`
+	_, err := tx.Exec(query, boardID)
 	return err
 }
+
+// checkParentCycle refuses a parent that is the task itself or one of its descendants
+func checkParentCycle(tx *sql.Tx, t *models.Task) error {
+	if err := lockTaskGraph(tx, t.BoardID); err != nil {
+		return err
+	}
+
+	// Walk up from the new parent; reaching the task means the task would become its own ancestor
+	cycleQuery := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_task_id FROM tasks WHERE id = $1
+			UNION
+			SELECT p.id, p.parent_task_id FROM tasks p JOIN ancestors a ON p.id = a.parent_task_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)
+	`
+	var cycle bool
+	if err := tx.QueryRow(cycleQuery, *t.ParentTaskID, t.ID).Scan(&cycle); err != nil {
+		return err
+	}
+	if cycle {
+		return ErrTaskCycle
+	}
+	return nil
+}
+
+// GetChildTasks lists a task's direct children, which may live on any board of the workspace
+func (r *TaskRepository) GetChildTasks(parentID int) ([]*models.TaskResponse, error) {
+	query := taskResponseQuery + `
+		WHERE t.parent_task_id = $1 AND t.active_status = 1
+		ORDER BY t.created_at ASC, t.id ASC
+	`
+	rows, err := r.DB.Query(query, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*models.TaskResponse
+	for rows.Next() {
+		tr, err := scanTaskResponse(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, tr)
+	}
+	return tasks, rows.Err()
+}
+
+// GetSubtaskRollup counts a task's direct children and all of its active descendants by status category
+func (r *TaskRepository) GetSubtaskRollup(taskID int) (int, *models.TaskRollup, error) {
+	var childCount int
+	countQuery := `SELECT COUNT(*) FROM tasks WHERE parent_task_id = $1 AND active_status = 1`
+	if err := r.DB.QueryRow(countQuery, taskID).Scan(&childCount); err != nil {
+		return 0, nil, err
+	}
+
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id FROM tasks WHERE parent_task_id = $1
+			UNION
+			SELECT t.id FROM tasks t JOIN descendants d ON t.parent_task_id = d.id
+		)
+		SELECT s.category, COUNT(*)
+		FROM descendants d
+		JOIN tasks t ON t.id = d.id
+		JOIN statuses s ON t.status_id = s.id
+		WHERE t.active_status = 1
+		GROUP BY s.category
+	`
+	rows, err := r.DB.Query(query, taskID)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	rollup := &models.TaskRollup{
+		ByCategory: map[string]int{
+			models.StatusCategoryBacklog:    0,
+			models.StatusCategoryTodo:       0,
+			models.StatusCategoryInProgress: 0,
+			models.StatusCategoryDone:       0,
+		},
+	}
+	for rows.Next() {
+		var (
+			category string
+			count    int
+		)
+		if err := rows.Scan(&category, &count); err != nil {
+			return 0, nil, err
+		}
+		rollup.ByCategory[category] = count
+		rollup.Total += count
+	}
+	return childCount, rollup, rows.Err()
+}
@@ -29,7 +29,7 @@ func (r *UserRepository) CreateUser(user *models.User) error {
 func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, external_id, name, email, password, avatar_url, active_status, created_at, created_by, modified_at, modified_by
+		SELECT id, external_id, name, email, password, avatar_url, email_verified_at, is_admin, active_status, created_at, created_by, modified_at, modified_by
 		FROM users
 		WHERE email = $1 AND active_status = 1
 	`
@@ -40,6 +40,8 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 		&user.Email,
 		&user.Password,
 		&user.AvatarURL,
+		&user.EmailVerifiedAt,
+		&user.IsAdmin,
 		&user.ActiveStatus,
 		&user.CreatedAt,
 		&user.CreatedBy,
@@ -56,7 +58,7 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 func (r *UserRepository) GetUserByExternalID(externalID string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, external_id, name, email, password, avatar_url, active_status, created_at, created_by, modified_at, modified_by
+		SELECT id, external_id, name, email, password, avatar_url, email_verified_at, is_admin, active_status, created_at, created_by, modified_at, modified_by
 		FROM users
 		WHERE external_id = $1 AND active_status = 1
 	`
@@ -67,6 +69,8 @@ func (r *UserRepository) GetUserByExternalID(externalID string) (*models.User, e
 		&user.Email,
 		&user.Password,
 		&user.AvatarURL,
+		&user.EmailVerifiedAt,
+		&user.IsAdmin,
 		&user.ActiveStatus,
 		&user.CreatedAt,
 		&user.CreatedBy,
@@ -83,7 +87,7 @@ func (r *UserRepository) GetUserByExternalID(externalID string) (*models.User, e
 func (r *UserRepository) GetUserByID(id int) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, external_id, name, email, password, avatar_url, active_status, created_at, created_by, modified_at, modified_by
+		SELECT id, external_id, name, email, password, avatar_url, email_verified_at, is_admin, active_status, created_at, created_by, modified_at, modified_by
 		FROM users
 		WHERE id = $1 AND active_status = 1
 	`
@@ -94,6 +98,8 @@ func (r *UserRepository) GetUserByID(id int) (*models.User, error) {
 		&user.Email,
 		&user.Password,
 		&user.AvatarURL,
+		&user.EmailVerifiedAt,
+		&user.IsAdmin,
 		&user.ActiveStatus,
 		&user.CreatedAt,
 		&user.CreatedBy,
@@ -110,9 +116,42 @@ func (r *UserRepository) GetUserByID(id int) (*models.User, error) {
 func (r *UserRepository) UpdateUserProfile(user *models.User) error {
 	query := `
 		UPDATE users
-		SET name = $1, avatar_url = $2, modified_at = NOW()
+		SET name = $1, avatar_url = $2, modified_at = NOW(), modified_by = $3
+		WHERE id = $4
+		RETURNING modified_at
+	`
+	return r.DB.QueryRow(query, user.Name, user.AvatarURL, user.ModifiedBy, user.ID).Scan(&user.ModifiedAt)
+}
+
+// UpdateUserPassword replaces a user's password hash
+func (r *UserRepository) UpdateUserPassword(user *models.User) error {
+	query := `
+		UPDATE users
+		SET password = $1, modified_at = NOW(), modified_by = $2
 		WHERE id = $3
 		RETURNING modified_at
 	`
-	return r.DB.QueryRow(query, user.Name, user.AvatarURL, user.ID).Scan(&user.ModifiedAt)
+	return r.DB.QueryRow(query, user.Password, user.ModifiedBy, user.ID).Scan(&user.ModifiedAt)
+}
+
+// UpdateUserEmail changes a user's email; the new address counts as verified since it was just confirmed
+func (r *UserRepository) UpdateUserEmail(user *models.User) error {
+	query := `
+		UPDATE users
+		SET email = $1, email_verified_at = NOW(), modified_at = NOW(), modified_by = $2
+		WHERE id = $3
+		RETURNING email_verified_at, modified_at
+	`
+	return r.DB.QueryRow(query, user.Email, user.ModifiedBy, user.ID).Scan(&user.EmailVerifiedAt, &user.ModifiedAt)
+}
+
+// MarkEmailVerified records that the user proved ownership of their email address
+func (r *UserRepository) MarkEmailVerified(user *models.User) error {
+	query := `
+		UPDATE users
+		SET email_verified_at = NOW(), modified_at = NOW()
+		WHERE id = $1
+		RETURNING email_verified_at, modified_at
+	`
+	return r.DB.QueryRow(query, user.ID).Scan(&user.EmailVerifiedAt, &user.ModifiedAt)
 }
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+type UserTokenRepository struct {
+	DB *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) *UserTokenRepository {
+	return &UserTokenRepository{DB: db}
+}
+
+// CreateUserToken stores a new hashed single-use token that expires after ttl
+func (r *UserTokenRepository) CreateUserToken(t *models.UserToken, ttl time.Duration) error {
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, new_email, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+		RETURNING id, expires_at, created_at
+	`
+	return r.DB.QueryRow(query, t.UserID, t.Purpose, t.TokenHash, t.NewEmail, int64(ttl.Seconds())).
+		Scan(&t.ID, &t.ExpiresAt, &t.CreatedAt)
+}
+
+// GetActiveUserToken retrieves an unused, unexpired token by hash and purpose
+func (r *UserTokenRepository) GetActiveUserToken(tokenHash, purpose string) (*models.UserToken, error) {
+	t := &models.UserToken{}
+	query := `
+		SELECT id, user_id, purpose, token_hash, new_email, expires_at, used_at, created_at
+		FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+	`
+	err := r.DB.QueryRow(query, tokenHash, purpose).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.Purpose,
+		&t.TokenHash,
+		&t.NewEmail,
+		&t.ExpiresAt,
+		&t.UsedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ConsumeUserToken marks a token as used. It returns false if the token was already used.
+func (r *UserTokenRepository) ConsumeUserToken(id int) (bool, error) {
+	query := `UPDATE user_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`
+	res, err := r.DB.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// InvalidateUserTokens marks every outstanding token of a purpose as used
+func (r *UserTokenRepository) InvalidateUserTokens(userID int, purpose string) error {
+	query := `UPDATE user_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+	_, err := r.DB.Exec(query, userID, purpose)
+	return err
+}
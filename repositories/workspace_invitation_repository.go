@@ -0,0 +1,245 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+// ErrInvitationNotPending is returned when an invitation was accepted, revoked or expired concurrently
+var ErrInvitationNotPending = errors.New("invitation is no longer pending")
+
+type WorkspaceInvitationRepository struct {
+	DB *sql.DB
+}
+
+func NewWorkspaceInvitationRepository(db *sql.DB) *WorkspaceInvitationRepository {
+	return &WorkspaceInvitationRepository{DB: db}
+}
+
+// invitationQuery selects an invitation joined with its workspace and inviter
+const invitationQuery = `
+	SELECT
+		i.id, i.external_id, i.workspace_id, w.external_id, w.name, i.email, i.role, i.token_hash, i.status,
+		i.invited_by_id, u.external_id, u.name, i.accepted_by_id, i.expires_at, i.accepted_at,
+		i.expires_at <= NOW(), i.created_at, i.created_by, i.modified_at, i.modified_by
+	FROM workspace_invitations i
+	JOIN workspaces w ON i.workspace_id = w.id
+	LEFT JOIN users u ON i.invited_by_id = u.id
+`
+
+// scanInvitation maps a row selected with invitationQuery into a WorkspaceInvitation
+func scanInvitation(row rowScanner) (*models.WorkspaceInvitation, error) {
+	inv := &models.WorkspaceInvitation{}
+	err := row.Scan(
+		&inv.ID,
+		&inv.ExternalID,
+		&inv.WorkspaceID,
+		&inv.WorkspaceExternalID,
+		&inv.WorkspaceName,
+		&inv.Email,
+		&inv.Role,
+		&inv.TokenHash,
+		&inv.Status,
+		&inv.InvitedByID,
+		&inv.InvitedByExternalID,
+		&inv.InvitedByName,
+		&inv.AcceptedByID,
+		&inv.ExpiresAt,
+		&inv.AcceptedAt,
+		&inv.Expired,
+		&inv.CreatedAt,
+		&inv.CreatedBy,
+		&inv.ModifiedAt,
+		&inv.ModifiedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// CreateInvitation stores a pending invitation, closing out any expired one for the same address first
+func (r *WorkspaceInvitationRepository) CreateInvitation(inv *models.WorkspaceInvitation, ttl time.Duration) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	expireQuery := `
+		UPDATE workspace_invitations
+		SET status = 'expired', modified_at = NOW()
+		WHERE workspace_id = $1 AND LOWER(email) = LOWER($2) AND status = 'pending' AND expires_at <= NOW()
+	`
+	if _, err := tx.Exec(expireQuery, inv.WorkspaceID, inv.Email); err != nil {
+		return err
+	}
+
+	insertQuery := `
+		INSERT INTO workspace_invitations (external_id, workspace_id, email, role, token_hash, invited_by_id, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW() + make_interval(secs => $7), $8)
+		RETURNING id, status, expires_at, created_at
+	`
+	err = tx.QueryRow(
+		insertQuery,
+		inv.ExternalID,
+		inv.WorkspaceID,
+		inv.Email,
+		inv.Role,
+		inv.TokenHash,
+		inv.InvitedByID,
+		int64(ttl.Seconds()),
+		inv.CreatedBy,
+	).Scan(&inv.ID, &inv.Status, &inv.ExpiresAt, &inv.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetInvitationsByWorkspaceID lists every invitation of a workspace, newest first
+func (r *WorkspaceInvitationRepository) GetInvitationsByWorkspaceID(workspaceID int) ([]*models.WorkspaceInvitation, error) {
+	query := invitationQuery + `
+		WHERE i.workspace_id = $1
+		ORDER BY i.created_at DESC
+	`
+	rows, err := r.DB.Query(query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []*models.WorkspaceInvitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+	return invitations, nil
+}
+
+// GetInvitationByExternalID retrieves an invitation within a workspace
+func (r *WorkspaceInvitationRepository) GetInvitationByExternalID(workspaceID int, externalID string) (*models.WorkspaceInvitation, error) {
+	query := invitationQuery + `
+		WHERE i.workspace_id = $1 AND i.external_id = $2
+	`
+	return scanInvitation(r.DB.QueryRow(query, workspaceID, externalID))
+}
+
+// GetInvitationByTokenHash retrieves an invitation from the hash of its link token
+func (r *WorkspaceInvitationRepository) GetInvitationByTokenHash(tokenHash string) (*models.WorkspaceInvitation, error) {
+	query := invitationQuery + `
+		WHERE i.token_hash = $1
+	`
+	return scanInvitation(r.DB.QueryRow(query, tokenHash))
+}
+
+// RenewInvitation replaces the link token of a pending invitation and restarts its expiry
+func (r *WorkspaceInvitationRepository) RenewInvitation(inv *models.WorkspaceInvitation, ttl time.Duration) error {
+	query := `
+		UPDATE workspace_invitations
+		SET token_hash = $1, expires_at = NOW() + make_interval(secs => $2), modified_at = NOW(), modified_by = $3
+		WHERE id = $4 AND status = 'pending'
+		RETURNING expires_at, modified_at
+	`
+	err := r.DB.QueryRow(query, inv.TokenHash, int64(ttl.Seconds()), inv.ModifiedBy, inv.ID).
+		Scan(&inv.ExpiresAt, &inv.ModifiedAt)
+	if err == sql.ErrNoRows {
+		return ErrInvitationNotPending
+	}
+	return err
+}
+
+// RevokeInvitation cancels a pending invitation
+func (r *WorkspaceInvitationRepository) RevokeInvitation(inv *models.WorkspaceInvitation) error {
+	query := `
+		UPDATE workspace_invitations
+		SET status = 'revoked', modified_at = NOW(), modified_by = $1
+		WHERE id = $2 AND status = 'pending'
+	`
+	res, err := r.DB.Exec(query, inv.ModifiedBy, inv.ID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInvitationNotPending
+	}
+	return nil
+}
+
+// AcceptInvitation marks the invitation accepted and adds the user to the workspace in one transaction
+func (r *WorkspaceInvitationRepository) AcceptInvitation(inv *models.WorkspaceInvitation, userID int, acceptedBy string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := acceptInvitation(tx, inv, userID, acceptedBy); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RegisterWithInvitation creates the invited user and accepts the invitation in one transaction,
+// so a failed acceptance leaves no account behind. Receiving the invitation proves the user
+// controls the address, so the email is stored as verified.
+func (r *WorkspaceInvitationRepository) RegisterWithInvitation(inv *models.WorkspaceInvitation, user *models.User) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	userQuery := `
+		INSERT INTO users (external_id, name, email, password, email_verified_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, email_verified_at, created_at
+	`
+	err = tx.QueryRow(userQuery, user.ExternalID, user.Name, user.Email, user.Password).
+		Scan(&user.ID, &user.EmailVerifiedAt, &user.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := acceptInvitation(tx, inv, user.ID, user.ExternalID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// acceptInvitation claims a pending invitation and adds the member within the caller's transaction
+func acceptInvitation(tx *sql.Tx, inv *models.WorkspaceInvitation, userID int, acceptedBy string) error {
+	acceptQuery := `
+		UPDATE workspace_invitations
+		SET status = 'accepted', accepted_by_id = $1, accepted_at = NOW(), modified_at = NOW(), modified_by = $2
+		WHERE id = $3 AND status = 'pending' AND expires_at > NOW()
+		RETURNING accepted_at
+	`
+	err := tx.QueryRow(acceptQuery, userID, acceptedBy, inv.ID).Scan(&inv.AcceptedAt)
+	if err == sql.ErrNoRows {
+		return ErrInvitationNotPending
+	}
+	if err != nil {
+		return err
+	}
+
+	wm := &models.WorkspaceMember{WorkspaceID: inv.WorkspaceID, UserID: userID, Role: inv.Role, CreatedBy: &acceptedBy}
+	if err := addMember(tx, wm); err != nil {
+		return err
+	}
+
+	inv.Status = models.InvitationAccepted
+	inv.AcceptedByID = &userID
+	return nil
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+// ErrJoinLinkInactive is returned when a link was revoked, expired or used up concurrently
+var ErrJoinLinkInactive = errors.New("join link is no longer active")
+
+type WorkspaceJoinLinkRepository struct {
+	DB *sql.DB
+}
+
+func NewWorkspaceJoinLinkRepository(db *sql.DB) *WorkspaceJoinLinkRepository {
+	return &WorkspaceJoinLinkRepository{DB: db}
+}
+
+// joinLinkQuery selects a join link joined with its workspace and creator
+const joinLinkQuery = `
+	SELECT
+		l.id, l.external_id, l.workspace_id, w.external_id, w.name, l.code, l.role, l.max_uses, l.use_count,
+		l.expires_at, l.revoked_at, l.expires_at <= NOW(), l.created_by_id, u.external_id,
+		l.created_at, l.created_by, l.modified_at, l.modified_by
+	FROM workspace_join_links l
+	JOIN workspaces w ON l.workspace_id = w.id
+	LEFT JOIN users u ON l.created_by_id = u.id
+`
+
+// scanJoinLink maps a row selected with joinLinkQuery into a WorkspaceJoinLink
+func scanJoinLink(row rowScanner) (*models.WorkspaceJoinLink, error) {
+	link := &models.WorkspaceJoinLink{}
+	err := row.Scan(
+		&link.ID,
+		&link.ExternalID,
+		&link.WorkspaceID,
+		&link.WorkspaceExternalID,
+		&link.WorkspaceName,
+		&link.Code,
+		&link.Role,
+		&link.MaxUses,
+		&link.UseCount,
+		&link.ExpiresAt,
+		&link.RevokedAt,
+		&link.Expired,
+		&link.CreatedByID,
+		&link.CreatedByExternalID,
+		&link.CreatedAt,
+		&link.CreatedBy,
+		&link.ModifiedAt,
+		&link.ModifiedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// CreateJoinLink stores a new join link that expires after ttl
+func (r *WorkspaceJoinLinkRepository) CreateJoinLink(link *models.WorkspaceJoinLink, ttl time.Duration) error {
+	query := `
+		INSERT INTO workspace_join_links (external_id, workspace_id, code, role, max_uses, expires_at, created_by_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, NOW() + make_interval(secs => $6), $7, $8)
+		RETURNING id, use_count, expires_at, created_at
+	`
+	return r.DB.QueryRow(
+		query,
+		link.ExternalID,
+		link.WorkspaceID,
+		link.Code,
+		link.Role,
+		link.MaxUses,
+		int64(ttl.Seconds()),
+		link.CreatedByID,
+		link.CreatedBy,
+	).Scan(&link.ID, &link.UseCount, &link.ExpiresAt, &link.CreatedAt)
+}
+
+// GetJoinLinksByWorkspaceID lists every join link of a workspace, newest first
+func (r *WorkspaceJoinLinkRepository) GetJoinLinksByWorkspaceID(workspaceID int) ([]*models.WorkspaceJoinLink, error) {
+	query := joinLinkQuery + `
+		WHERE l.workspace_id = $1
+		ORDER BY l.created_at DESC
+	`
+	rows, err := r.DB.Query(query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*models.WorkspaceJoinLink
+	for rows.Next() {
+		link, err := scanJoinLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// GetJoinLinkByExternalID retrieves a join link within a workspace
+func (r *WorkspaceJoinLinkRepository) GetJoinLinkByExternalID(workspaceID int, externalID string) (*models.WorkspaceJoinLink, error) {
+	query := joinLinkQuery + `
+		WHERE l.workspace_id = $1 AND l.external_id = $2
+	`
+	return scanJoinLink(r.DB.QueryRow(query, workspaceID, externalID))
+}
+
+// GetJoinLinkByCode retrieves a join link of an active workspace from its shareable code
+func (r *WorkspaceJoinLinkRepository) GetJoinLinkByCode(code string) (*models.WorkspaceJoinLink, error) {
+	query := joinLinkQuery + `
+		WHERE l.code = $1 AND w.active_status = 1
+	`
+	return scanJoinLink(r.DB.QueryRow(query, code))
+}
+
+// RevokeJoinLink disables a join link; revoking twice is a no-op
+func (r *WorkspaceJoinLinkRepository) RevokeJoinLink(link *models.WorkspaceJoinLink) error {
+	query := `
+		UPDATE workspace_join_links
+		SET revoked_at = COALESCE(revoked_at, NOW()), modified_at = NOW(), modified_by = $1
+		WHERE id = $2
+		RETURNING revoked_at, modified_at
+	`
+	return r.DB.QueryRow(query, link.ModifiedBy, link.ID).Scan(&link.RevokedAt, &link.ModifiedAt)
+}
+
+// JoinWithLink claims one use of a link, adds the member and records the use in one
+// transaction, so a failed join neither burns a use nor leaves a member unrecorded.
+// It returns ErrJoinLinkInactive when the link was revoked, expired or used up, so
+// concurrent joins cannot exceed max_uses.
+func (r *WorkspaceJoinLinkRepository) JoinWithLink(link *models.WorkspaceJoinLink, wm *models.WorkspaceMember) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	claimQuery := `
+		UPDATE workspace_join_links
+		SET use_count = use_count + 1
+		WHERE id = $1
+			AND revoked_at IS NULL
+			AND expires_at > NOW()
+			AND (max_uses IS NULL OR use_count < max_uses)
+		RETURNING use_count
+	`
+	err = tx.QueryRow(claimQuery, link.ID).Scan(&link.UseCount)
+	if err == sql.ErrNoRows {
+		return ErrJoinLinkInactive
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := addMember(tx, wm); err != nil {
+		return err
+	}
+
+	useQuery := `INSERT INTO workspace_join_link_uses (join_link_id, user_id) VALUES ($1, $2)`
+	if _, err := tx.Exec(useQuery, link.ID, wm.UserID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetJoinLinkUses lists the users who joined through a link, most recent first
+func (r *WorkspaceJoinLinkRepository) GetJoinLinkUses(linkID int) ([]*models.JoinLinkUseResponse, error) {
+	query := `
+		SELECT u.external_id, u.name, u.email, lu.joined_at
+		FROM workspace_join_link_uses lu
+		JOIN users u ON lu.user_id = u.id
+		WHERE lu.join_link_id = $1
+		ORDER BY lu.joined_at DESC
+	`
+	rows, err := r.DB.Query(query, linkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var uses []*models.JoinLinkUseResponse
+	for rows.Next() {
+		u := &models.JoinLinkUseResponse{}
+		if err := rows.Scan(&u.UserExternalID, &u.Name, &u.Email, &u.JoinedAt); err != nil {
+			return nil, err
+		}
+		uses = append(uses, u)
+	}
+	return uses, nil
+}
@@ -2,10 +2,18 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 
 	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/lib/pq"
 )
 
+// ErrOwnerChanged is returned when the workspace changed hands while a transfer was in progress
+var ErrOwnerChanged = errors.New("workspace owner has changed")
+
+// ErrNotMember is returned when the target of a membership operation is not in the workspace
+var ErrNotMember = errors.New("user is not a member of the workspace")
+
 type WorkspaceRepository struct {
 	DB *sql.DB
 }
@@ -14,8 +22,9 @@ func NewWorkspaceRepository(db *sql.DB) *WorkspaceRepository {
 	return &WorkspaceRepository{DB: db}
 }
 
-// CreateWorkspace creates a new workspace and adds the owner to the workspace_members table
-func (r *WorkspaceRepository) CreateWorkspace(workspace *models.Workspace) error {
+// CreateWorkspace creates a new workspace with its built-in roles and default statuses,
+// and adds the owner to the workspace_members table
+func (r *WorkspaceRepository) CreateWorkspace(workspace *models.Workspace, roles []*models.WorkspaceRole, statuses []*models.Status) error {
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return err
@@ -34,19 +43,74 @@ func (r *WorkspaceRepository) CreateWorkspace(workspace *models.Workspace) error
 		return err
 	}
 
-	// Insert owner into workspace_members
-	memberQuery := `
-		INSERT INTO workspace_members (workspace_id, user_id, role)
-		VALUES ($1, $2, 'owner')
+	// Seed the roles members will reference
+	roleQuery := `
+		INSERT INTO workspace_roles (external_id, workspace_id, name, description, permissions, is_builtin, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
 	`
-	_, err = tx.Exec(memberQuery, workspace.ID, workspace.OwnerID)
-	if err != nil {
+	for _, role := range roles {
+		role.WorkspaceID = workspace.ID
+		err = tx.QueryRow(
+			roleQuery,
+			role.ExternalID,
+			role.WorkspaceID,
+			role.Name,
+			role.Description,
+			pq.Array(role.Permissions),
+			role.IsBuiltin,
+			role.CreatedBy,
+		).Scan(&role.ID, &role.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Seed the workspace's default status set
+	seedStatusQuery := `
+		INSERT INTO statuses (external_id, workspace_id, name, color, category, position, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	for _, status := range statuses {
+		status.WorkspaceID = workspace.ID
+		err = tx.QueryRow(
+			seedStatusQuery,
+			status.ExternalID,
+			status.WorkspaceID,
+			status.Name,
+			status.Color,
+			status.Category,
+			status.Position,
+			status.CreatedBy,
+		).Scan(&status.ID, &status.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if err := recordStatusAudit(tx, status, models.StatusAuditCreated, nil, snapshotStatus(status), &workspace.OwnerID, status.CreatedBy); err != nil {
+			return err
+		}
+	}
+
+	// Insert owner into workspace_members
+	owner := &models.WorkspaceMember{WorkspaceID: workspace.ID, UserID: workspace.OwnerID, Role: models.RoleOwner}
+	if err := addMember(tx, owner); err != nil {
 		return err
 	}
 
 	return tx.Commit()
 }
 
+// addMember adds a user to a workspace within the transaction that grants the membership
+func addMember(tx *sql.Tx, wm *models.WorkspaceMember) error {
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, joined_at
+	`
+	return tx.QueryRow(query, wm.WorkspaceID, wm.UserID, wm.Role, wm.CreatedBy).Scan(&wm.ID, &wm.JoinedAt)
+}
+
 // GetWorkspacesByUserID retrieves all workspaces a user is a member of
 func (r *WorkspaceRepository) GetWorkspacesByUserID(userID int) ([]*models.Workspace, error) {
 	query := `
@@ -121,21 +185,29 @@ func (r *WorkspaceRepository) UpdateWorkspace(w *models.Workspace) error {
 	return r.DB.QueryRow(query, w.Name, w.Description, w.ID).Scan(&w.ModifiedAt)
 }
 
-// DeleteWorkspace deletes a workspace
-func (r *WorkspaceRepository) DeleteWorkspace(id int) error {
-	query := `DELETE FROM workspaces WHERE id = $1`
-	_, err := r.DB.Exec(query, id)
-	return err
-}
+// DeleteWorkspace deletes a workspace and returns the storage keys of its tasks' attachments for blob cleanup
+func (r *WorkspaceRepository) DeleteWorkspace(id int) ([]string, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-// AddMember adds a user to a workspace
-func (r *WorkspaceRepository) AddMember(wm *models.WorkspaceMember) error {
-	query := `
-		INSERT INTO workspace_members (workspace_id, user_id, role)
-		VALUES ($1, $2, $3)
-		RETURNING id, joined_at
-	`
-	return r.DB.QueryRow(query, wm.WorkspaceID, wm.UserID, wm.Role).Scan(&wm.ID, &wm.JoinedAt)
+	taskFilter := `SELECT t.id FROM tasks t JOIN boards b ON t.board_id = b.id WHERE b.workspace_id = $1`
+	if _, err := tx.Exec(taskFilter+` FOR UPDATE OF t`, id); err != nil {
+		return nil, err
+	}
+
+	keys, err := deleteAttachmentsReturningKeys(tx, taskFilter, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM workspaces WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+
+	return keys, tx.Commit()
 }
 
 // GetMembers retrieves all members of a workspace
@@ -175,19 +247,144 @@ func (r *WorkspaceRepository) GetMemberRole(workspaceID, userID int) (string, er
 }
 
 // UpdateMemberRole updates a user's role in a workspace
-func (r *WorkspaceRepository) UpdateMemberRole(workspaceID, userID int, role string) error {
+func (r *WorkspaceRepository) UpdateMemberRole(workspaceID, userID int, role, modifiedBy string) error {
 	query := `
 		UPDATE workspace_members
-		SET role = $1, modified_at = NOW()
-		WHERE workspace_id = $2 AND user_id = $3
+		SET role = $1, modified_at = NOW(), modified_by = $2
+		WHERE workspace_id = $3 AND user_id = $4
 	`
-	_, err := r.DB.Exec(query, role, workspaceID, userID)
+	_, err := r.DB.Exec(query, role, modifiedBy, workspaceID, userID)
 	return err
 }
 
-// RemoveMember removes a user from a workspace
-func (r *WorkspaceRepository) RemoveMember(workspaceID, userID int) error {
-	query := `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
-	_, err := r.DB.Exec(query, workspaceID, userID)
-	return err
+// RemoveMember removes a user from a workspace and unassigns the workspace's tasks and checklist items they held
+func (r *WorkspaceRepository) RemoveMember(workspaceID, userID int, modifiedBy string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotMember
+	}
+
+	unassignQuery := `
+		UPDATE tasks
+		SET assigned_to = NULL, modified_at = NOW(), modified_by = $1
+		WHERE assigned_to = $2 AND board_id IN (SELECT id FROM boards WHERE workspace_id = $3)
+	`
+	if _, err := tx.Exec(unassignQuery, modifiedBy, userID, workspaceID); err != nil {
+		return err
+	}
+
+	unassignItemsQuery := `
+		UPDATE task_checklist_items
+		SET assigned_to = NULL, modified_at = NOW(), modified_by = $1
+		WHERE assigned_to = $2 AND task_id IN (
+			SELECT t.id FROM tasks t JOIN boards b ON t.board_id = b.id WHERE b.workspace_id = $3
+		)
+	`
+	if _, err := tx.Exec(unassignItemsQuery, modifiedBy, userID, workspaceID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// TransferOwnership hands the workspace to another member in one transaction: the new owner is
+// promoted to owner and the previous owner stays on as admin
+func (r *WorkspaceRepository) TransferOwnership(w *models.Workspace, newOwnerID int, modifiedBy string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	previousOwnerID := w.OwnerID
+
+	// Only succeeds if nobody transferred the workspace in the meantime
+	workspaceQuery := `
+		UPDATE workspaces
+		SET owner_id = $1, modified_at = NOW(), modified_by = $2
+		WHERE id = $3 AND owner_id = $4
+		RETURNING modified_at
+	`
+	err = tx.QueryRow(workspaceQuery, newOwnerID, modifiedBy, w.ID, previousOwnerID).Scan(&w.ModifiedAt)
+	if err == sql.ErrNoRows {
+		return ErrOwnerChanged
+	}
+	if err != nil {
+		return err
+	}
+
+	roleQuery := `
+		UPDATE workspace_members
+		SET role = $1, modified_at = NOW(), modified_by = $2
+		WHERE workspace_id = $3 AND user_id = $4
+	`
+	res, err := tx.Exec(roleQuery, models.RoleOwner, modifiedBy, w.ID, newOwnerID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotMember
+	}
+
+	if _, err := tx.Exec(roleQuery, models.RoleAdmin, modifiedBy, w.ID, previousOwnerID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	w.OwnerID = newOwnerID
+	return nil
+}
+
+// GetMemberAccess gets a user's role in a workspace together with the permissions it grants
+func (r *WorkspaceRepository) GetMemberAccess(workspaceID, userID int) (*models.MemberAccess, error) {
+	access := &models.MemberAccess{}
+	query := `
+		SELECT wm.role, wr.permissions
+		FROM workspace_members wm
+		JOIN workspace_roles wr ON wr.workspace_id = wm.workspace_id AND wr.name = wm.role
+		WHERE wm.workspace_id = $1 AND wm.user_id = $2
+	`
+	err := r.DB.QueryRow(query, workspaceID, userID).Scan(&access.Role, pq.Array(&access.Permissions))
+	if err != nil {
+		return nil, err
+	}
+	return access, nil
+}
+
+// GetMemberAccessByExternalIDs is GetMemberAccess for an active workspace addressed by external IDs (used by middleware)
+func (r *WorkspaceRepository) GetMemberAccessByExternalIDs(workspaceExternalID, userExternalID string) (*models.MemberAccess, error) {
+	access := &models.MemberAccess{}
+	query := `
+		SELECT wm.role, wr.permissions
+		FROM workspace_members wm
+		JOIN workspaces w ON wm.workspace_id = w.id
+		JOIN users u ON wm.user_id = u.id
+		JOIN workspace_roles wr ON wr.workspace_id = wm.workspace_id AND wr.name = wm.role
+		WHERE w.external_id = $1 AND u.external_id = $2 AND w.active_status = 1
+	`
+	err := r.DB.QueryRow(query, workspaceExternalID, userExternalID).Scan(&access.Role, pq.Array(&access.Permissions))
+	if err != nil {
+		return nil, err
+	}
+	return access, nil
 }
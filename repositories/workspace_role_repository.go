@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/lib/pq"
+)
+
+type WorkspaceRoleRepository struct {
+	DB *sql.DB
+}
+
+func NewWorkspaceRoleRepository(db *sql.DB) *WorkspaceRoleRepository {
+	return &WorkspaceRoleRepository{DB: db}
+}
+
+// roleQuery selects a role with the number of members currently holding it
+const roleQuery = `
+	SELECT
+		r.id, r.external_id, r.workspace_id, r.name, r.description, r.permissions, r.is_builtin,
+		(SELECT COUNT(*) FROM workspace_members wm WHERE wm.workspace_id = r.workspace_id AND wm.role = r.name),
+		r.created_at, r.created_by, r.modified_at, r.modified_by
+	FROM workspace_roles r
+`
+
+// scanRole maps a row selected with roleQuery into a WorkspaceRole
+func scanRole(row rowScanner) (*models.WorkspaceRole, error) {
+	role := &models.WorkspaceRole{}
+	err := row.Scan(
+		&role.ID,
+		&role.ExternalID,
+		&role.WorkspaceID,
+		&role.Name,
+		&role.Description,
+		pq.Array(&role.Permissions),
+		&role.IsBuiltin,
+		&role.MemberCount,
+		&role.CreatedAt,
+		&role.CreatedBy,
+		&role.ModifiedAt,
+		&role.ModifiedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// GetRolesByWorkspaceID lists the roles of a workspace, built-in roles first
+func (r *WorkspaceRoleRepository) GetRolesByWorkspaceID(workspaceID int) ([]*models.WorkspaceRole, error) {
+	query := roleQuery + `
+		WHERE r.workspace_id = $1
+		ORDER BY r.is_builtin DESC, r.id
+	`
+	rows, err := r.DB.Query(query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []*models.WorkspaceRole
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// GetRoleByExternalID retrieves a role within a workspace
+func (r *WorkspaceRoleRepository) GetRoleByExternalID(workspaceID int, externalID string) (*models.WorkspaceRole, error) {
+	query := roleQuery + `
+		WHERE r.workspace_id = $1 AND r.external_id = $2
+	`
+	return scanRole(r.DB.QueryRow(query, workspaceID, externalID))
+}
+
+// GetRoleByName retrieves a role within a workspace by the name members reference
+func (r *WorkspaceRoleRepository) GetRoleByName(workspaceID int, name string) (*models.WorkspaceRole, error) {
+	query := roleQuery + `
+		WHERE r.workspace_id = $1 AND r.name = $2
+	`
+	return scanRole(r.DB.QueryRow(query, workspaceID, name))
+}
+
+// CreateRole stores a custom role
+func (r *WorkspaceRoleRepository) CreateRole(role *models.WorkspaceRole) error {
+	query := `
+		INSERT INTO workspace_roles (external_id, workspace_id, name, description, permissions, is_builtin, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	return r.DB.QueryRow(
+		query,
+		role.ExternalID,
+		role.WorkspaceID,
+		role.Name,
+		role.Description,
+		pq.Array(role.Permissions),
+		role.IsBuiltin,
+		role.CreatedBy,
+	).Scan(&role.ID, &role.CreatedAt)
+}
+
+// UpdateRole saves a role. A rename cascades to members through the foreign key and is
+// carried over to pending invitations and join links in the same transaction.
+func (r *WorkspaceRoleRepository) UpdateRole(role *models.WorkspaceRole, previousName string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE workspace_roles
+		SET name = $1, description = $2, permissions = $3, modified_at = NOW(), modified_by = $4
+		WHERE id = $5
+		RETURNING modified_at
+	`
+	err = tx.QueryRow(query, role.Name, role.Description, pq.Array(role.Permissions), role.ModifiedBy, role.ID).
+		Scan(&role.ModifiedAt)
+	if err != nil {
+		return err
+	}
+
+	if role.Name != previousName {
+		invitationQuery := `
+			UPDATE workspace_invitations SET role = $1
+			WHERE workspace_id = $2 AND role = $3 AND status = 'pending'
+		`
+		if _, err := tx.Exec(invitationQuery, role.Name, role.WorkspaceID, previousName); err != nil {
+			return err
+		}
+
+		joinLinkQuery := `
+			UPDATE workspace_join_links SET role = $1
+			WHERE workspace_id = $2 AND role = $3 AND revoked_at IS NULL
+		`
+		if _, err := tx.Exec(joinLinkQuery, role.Name, role.WorkspaceID, previousName); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteRole removes a role; the foreign key refuses while members still hold it
+func (r *WorkspaceRoleRepository) DeleteRole(id int) error {
+	query := `DELETE FROM workspace_roles WHERE id = $1`
+	_, err := r.DB.Exec(query, id)
+	return err
+}
+
+// CountPendingRoleGrants counts pending invitations and live join links that would grant a role
+func (r *WorkspaceRoleRepository) CountPendingRoleGrants(workspaceID int, name string) (int, error) {
+	var count int
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM workspace_invitations
+				WHERE workspace_id = $1 AND role = $2 AND status = 'pending' AND expires_at > NOW())
+			+
+			(SELECT COUNT(*) FROM workspace_join_links
+				WHERE workspace_id = $1 AND role = $2 AND revoked_at IS NULL AND expires_at > NOW())
+	`
+	err := r.DB.QueryRow(query, workspaceID, name).Scan(&count)
+	return count, err
+}
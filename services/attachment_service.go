@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/storage"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+// sniffLen is how many leading bytes MIME detection looks at
+const sniffLen = 512
+
+type AttachmentService struct {
+	attachmentRepo *repositories.AttachmentRepository
+	blobs          storage.BlobStore
+	maxSize        int64
+	authz          *workspaceAuthorizer
+}
+
+func NewAttachmentService(attachmentRepo *repositories.AttachmentRepository, taskRepo *repositories.TaskRepository, boardRepo *repositories.BoardRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository, blobs storage.BlobStore, maxSize int64) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		blobs:          blobs,
+		maxSize:        maxSize,
+		authz:          newTaskAuthorizer(userRepo, workspaceRepo, boardRepo, taskRepo),
+	}
+}
+
+// MaxSize is the largest attachment accepted, in bytes
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// UploadAttachment stores an uploaded file on a task. The MIME type is sniffed from the contents,
+// and the checksum and size are computed while streaming into the blob store.
+func (s *AttachmentService) UploadAttachment(userExternalID, taskExternalID string, file *multipart.FileHeader) (*models.AttachmentResponse, error) {
+	user, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Size > s.maxSize {
+		return nil, newFieldError("file", fmt.Sprintf("must be at most %d MB", s.maxSize>>20))
+	}
+
+	filename := sanitizeFilename(file.Filename)
+	if filename == "" {
+		return nil, newFieldError("file", "must have a file name")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	attachment := &models.Attachment{
+		ExternalID:         utils.GenerateUUID(),
+		TaskID:             task.ID,
+		UploadedByID:       &user.ID,
+		UploaderExternalID: &user.ExternalID,
+		UploaderName:       &user.Name,
+		Filename:           filename,
+		MimeType:           http.DetectContentType(head),
+		CreatedBy:          &user.ExternalID,
+	}
+	attachment.StorageKey = "tasks/" + task.ExternalID + "/" + attachment.ExternalID
+
+	// Never trust the declared size: count while copying and stop one byte past the limit
+	hash := sha256.New()
+	counter := &countingWriter{}
+	body := io.LimitReader(io.MultiReader(bytes.NewReader(head), src), s.maxSize+1)
+	if err := s.blobs.Put(attachment.StorageKey, io.TeeReader(body, io.MultiWriter(hash, counter))); err != nil {
+		return nil, err
+	}
+	if counter.n > s.maxSize {
+		s.removeBlobs([]string{attachment.StorageKey})
+		return nil, newFieldError("file", fmt.Sprintf("must be at most %d MB", s.maxSize>>20))
+	}
+	attachment.SizeBytes = counter.n
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.attachmentRepo.CreateAttachment(attachment); err != nil {
+		s.removeBlobs([]string{attachment.StorageKey})
+		if repositories.IsForeignKeyViolation(err) {
+			return nil, newNotFound("task not found")
+		}
+		return nil, err
+	}
+
+	return mapAttachmentResponse(attachment, task.ExternalID), nil
+}
+
+// GetAttachments lists a task's attachments
+func (s *AttachmentService) GetAttachments(userExternalID, taskExternalID string) ([]*models.AttachmentResponse, error) {
+	_, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.GetAttachmentsByTaskID(task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []*models.AttachmentResponse{}
+	for _, a := range attachments {
+		response = append(response, mapAttachmentResponse(a, task.ExternalID))
+	}
+	return response, nil
+}
+
+// OpenAttachment authorizes a download and opens the blob for streaming; the caller must close it
+func (s *AttachmentService) OpenAttachment(userExternalID, taskExternalID, attachmentExternalID string) (*models.Attachment, io.ReadCloser, error) {
+	_, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := s.getAttachment(task.ID, attachmentExternalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blob, err := s.blobs.Open(attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, nil, newNotFound("attachment content not found")
+		}
+		return nil, nil, err
+	}
+
+	return attachment, blob, nil
+}
+
+// DeleteAttachment removes an attachment; the uploader may always, others need task:delete
+func (s *AttachmentService) DeleteAttachment(userExternalID, taskExternalID, attachmentExternalID string) error {
+	user, task, _, access, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return err
+	}
+
+	attachment, err := s.getAttachment(task.ID, attachmentExternalID)
+	if err != nil {
+		return err
+	}
+
+	isUploader := attachment.UploadedByID != nil && *attachment.UploadedByID == user.ID
+	if !isUploader && !policy.Can(access.Role, access.Permissions, policy.ActionDelete, policy.ResourceTask) {
+		return newForbidden(policy.DenialMessage(policy.ActionDelete, policy.ResourceTask))
+	}
+
+	if err := s.attachmentRepo.DeleteAttachment(attachment.ID); err != nil {
+		return err
+	}
+	s.removeBlobs([]string{attachment.StorageKey})
+	return nil
+}
+
+func (s *AttachmentService) getAttachment(taskID int, attachmentExternalID string) (*models.Attachment, error) {
+	attachment, err := s.attachmentRepo.GetAttachmentByExternalID(taskID, attachmentExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("attachment not found")
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *AttachmentService) removeBlobs(keys []string) {
+	removeBlobs(s.blobs, keys)
+}
+
+// removeBlobs deletes stored blobs after their records are gone. Failures only leave orphaned
+// files behind, so they are logged rather than failing the request.
+func removeBlobs(blobs storage.BlobStore, keys []string) {
+	for _, key := range keys {
+		if err := blobs.Delete(key); err != nil {
+			log.Printf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+// sanitizeFilename keeps only the base name of an uploaded file, without control characters
+func sanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return strings.TrimSpace(name)
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+func mapAttachmentResponse(a *models.Attachment, taskExternalID string) *models.AttachmentResponse {
+	response := &models.AttachmentResponse{
+		ExternalID:     a.ExternalID,
+		TaskExternalID: taskExternalID,
+		Filename:       a.Filename,
+		SizeBytes:      a.SizeBytes,
+		MimeType:       a.MimeType,
+		Checksum:       a.Checksum,
+		DownloadURL:    "/api/tasks/" + taskExternalID + "/attachments/" + a.ExternalID + "/download",
+		CreatedAt:      a.CreatedAt,
+	}
+	if a.UploaderExternalID != nil {
+		response.UploadedBy = &models.TaskUserInfo{
+			ExternalID: *a.UploaderExternalID,
+			Name:       *a.UploaderName,
+		}
+	}
+	return response
+}
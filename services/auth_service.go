@@ -2,27 +2,54 @@ package services
 
 import (
 	"database/sql"
-	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/grahagandangr/nexboard-be/config"
+	"github.com/grahagandangr/nexboard-be/mailer"
 	"github.com/grahagandangr/nexboard-be/models"
 	"github.com/grahagandangr/nexboard-be/repositories"
 	"github.com/grahagandangr/nexboard-be/utils"
 )
 
 type AuthService struct {
-	userRepo *repositories.UserRepository
+	userRepo         *repositories.UserRepository
+	refreshTokenRepo *repositories.RefreshTokenRepository
+	userTokenRepo    *repositories.UserTokenRepository
+	invitationRepo   *repositories.WorkspaceInvitationRepository
+	mailer           mailer.Mailer
 }
 
-func NewAuthService(userRepo *repositories.UserRepository) *AuthService {
-	return &AuthService{userRepo: userRepo}
+func NewAuthService(userRepo *repositories.UserRepository, refreshTokenRepo *repositories.RefreshTokenRepository, userTokenRepo *repositories.UserTokenRepository, invitationRepo *repositories.WorkspaceInvitationRepository, mail mailer.Mailer) *AuthService {
+	return &AuthService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		userTokenRepo:    userTokenRepo,
+		invitationRepo:   invitationRepo,
+		mailer:           mail,
+	}
 }
 
-// Register creates a new user account
+// Register creates a new user account. With an invitation token the user also
+// joins the inviting workspace, and the email counts as verified.
 func (s *AuthService) Register(req *models.RegisterRequest) (*models.UserResponse, error) {
 	// Check if user already exists
 	_, err := s.userRepo.GetUserByEmail(req.Email)
 	if err == nil {
-		return nil, errors.New("email already exists")
+		return nil, newConflict("email already exists")
+	}
+
+	var invitation *models.WorkspaceInvitation
+	if req.InvitationToken != nil {
+		invitation, err = s.invitationRepo.GetInvitationByTokenHash(utils.HashToken(*req.InvitationToken))
+		if err != nil || effectiveInvitationStatus(invitation) != models.InvitationPending {
+			return nil, newFieldError("invitation_token", "invalid or expired invitation")
+		}
+		if !strings.EqualFold(invitation.Email, req.Email) {
+			return nil, newFieldError("invitation_token", "invitation was sent to a different email address")
+		}
 	}
 
 	// Hash password
@@ -39,8 +66,23 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.UserRespons
 		Password:   hashedPassword,
 	}
 
-	if err := s.userRepo.CreateUser(user); err != nil {
-		return nil, err
+	if invitation != nil {
+		// The account and the membership are created together, so a failed acceptance leaves no account behind
+		if err := s.invitationRepo.RegisterWithInvitation(invitation, user); err != nil {
+			// A brand-new user cannot already be a member, so a duplicate can only be the email
+			if repositories.IsUniqueViolation(err) {
+				return nil, newConflict("email already exists")
+			}
+			return nil, invitationAcceptError(err)
+		}
+	} else {
+		if err := s.userRepo.CreateUser(user); err != nil {
+			return nil, err
+		}
+		if err := s.sendVerificationEmail(user); err != nil {
+			// The account exists either way; the user can ask for a new link
+			log.Printf("Failed to send verification email: %v", err)
+		}
 	}
 
 	return &models.UserResponse{
@@ -50,30 +92,36 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.UserRespons
 	}, nil
 }
 
-// Login authenticates a user and returns a JWT token
+// Login authenticates a user and starts a new session with an access and refresh token
 func (s *AuthService) Login(email, password string) (*models.LoginResponse, error) {
 	// Get user by email
 	user, err := s.userRepo.GetUserByEmail(email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("invalid email or password")
+			return nil, newUnauthorized("invalid email or password")
 		}
 		return nil, err
 	}
 
 	// Check password
 	if err := utils.CheckPassword(user.Password, password); err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, newUnauthorized("invalid email or password")
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ExternalID, user.Email)
+	if user.EmailVerifiedAt == nil && config.AppConfig.UnverifiedAccountPolicy == "block" {
+		return nil, newForbidden("email address has not been verified")
+	}
+
+	// Every login starts a new refresh token family
+	tokens, err := s.issueTokens(user, utils.GenerateUUID(), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.LoginResponse{
-		Token: token,
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 		User: models.UserResponse{
 			ExternalID: user.ExternalID,
 			Name:       user.Name,
@@ -82,12 +130,272 @@ func (s *AuthService) Login(email, password string) (*models.LoginResponse, erro
 	}, nil
 }
 
+// RefreshToken rotates a refresh token and issues a new access token.
+// Presenting an already rotated token is treated as theft and revokes the whole family.
+func (s *AuthService) RefreshToken(refreshToken string) (*models.TokenResponse, error) {
+	current, err := s.refreshTokenRepo.GetRefreshTokenByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newUnauthorized("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		if err := s.refreshTokenRepo.RevokeFamily(current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, newUnauthorized("refresh token reuse detected, session revoked")
+	}
+
+	if current.Expired {
+		return nil, newUnauthorized("refresh token expired")
+	}
+
+	user, err := s.userRepo.GetUserByID(current.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newUnauthorized("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	return s.issueTokens(user, current.FamilyID, current)
+}
+
+// Logout revokes the session the refresh token belongs to
+func (s *AuthService) Logout(refreshToken string) error {
+	current, err := s.refreshTokenRepo.GetRefreshTokenByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Unknown tokens are already unusable, nothing to revoke
+			return nil
+		}
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeFamily(current.FamilyID)
+}
+
+// ForgotPassword emails a single-use reset link. Unknown addresses are ignored
+// so the endpoint cannot be used to discover registered emails.
+func (s *AuthService) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	// Only the most recent link stays usable
+	if err := s.userTokenRepo.InvalidateUserTokens(user.ID, models.UserTokenPasswordReset); err != nil {
+		return err
+	}
+
+	plainToken, err := s.createUserToken(user.ID, models.UserTokenPasswordReset, config.AppConfig.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your NexBoard password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s/reset-password?token=%s\n\nThe link expires in %s. If you did not request this, you can ignore this email.\n",
+			user.Name, config.AppConfig.AppBaseURL, plainToken, config.AppConfig.PasswordResetTTL,
+		),
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		// Do not reveal delivery problems to the caller, it would confirm the account exists
+		log.Printf("Failed to send password reset email: %v", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password from a reset token and ends every existing session
+func (s *AuthService) ResetPassword(req *models.ResetPasswordRequest) error {
+	token, err := s.consumeUserToken(req.Token, models.UserTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetUserByID(token.UserID)
+	if err != nil {
+		return newNotFound("user not found")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	user.ModifiedBy = &user.ExternalID
+
+	if err := s.userRepo.UpdateUserPassword(user); err != nil {
+		return err
+	}
+
+	if err := s.userTokenRepo.InvalidateUserTokens(user.ID, models.UserTokenPasswordReset); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeAllForUser(user.ID)
+}
+
+// VerifyEmail confirms the user's email address from a verification token
+func (s *AuthService) VerifyEmail(plainToken string) error {
+	token, err := s.consumeUserToken(plainToken, models.UserTokenEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetUserByID(token.UserID)
+	if err != nil {
+		return newNotFound("user not found")
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.userRepo.MarkEmailVerified(user)
+}
+
+// ResendVerification emails a fresh verification link. Unknown or already verified
+// addresses are ignored so the endpoint cannot be used to discover accounts.
+func (s *AuthService) ResendVerification(email string) error {
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+	}
+	return nil
+}
+
+// sendVerificationEmail replaces any outstanding verification link with a new one
+func (s *AuthService) sendVerificationEmail(user *models.User) error {
+	if err := s.userTokenRepo.InvalidateUserTokens(user.ID, models.UserTokenEmailVerification); err != nil {
+		return err
+	}
+
+	plainToken, err := s.createUserToken(user.ID, models.UserTokenEmailVerification, config.AppConfig.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(&mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your NexBoard email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s/verify-email?token=%s\n\nThe link expires in %s.\n",
+			user.Name, config.AppConfig.AppBaseURL, plainToken, config.AppConfig.EmailVerificationTTL,
+		),
+	})
+}
+
+// createUserToken issues a hashed single-use token and returns its plain value
+func (s *AuthService) createUserToken(userID int, purpose string, ttl time.Duration) (string, error) {
+	plainToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	token := &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(plainToken),
+	}
+	if err := s.userTokenRepo.CreateUserToken(token, ttl); err != nil {
+		return "", err
+	}
+
+	return plainToken, nil
+}
+
+// consumeUserToken validates a plain token and marks it used so it cannot be replayed
+func (s *AuthService) consumeUserToken(plainToken, purpose string) (*models.UserToken, error) {
+	token, err := s.userTokenRepo.GetActiveUserToken(utils.HashToken(plainToken), purpose)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newFieldError("token", "invalid or expired token")
+		}
+		return nil, err
+	}
+
+	consumed, err := s.userTokenRepo.ConsumeUserToken(token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, newFieldError("token", "invalid or expired token")
+	}
+
+	return token, nil
+}
+
+// issueTokens creates an access token and a refresh token in the given family,
+// rotating the previous refresh token when one is supplied
+func (s *AuthService) issueTokens(user *models.User, familyID string, previous *models.RefreshToken) (*models.TokenResponse, error) {
+	plainRefresh, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	next := &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(plainRefresh),
+	}
+
+	ttl := config.AppConfig.RefreshTokenTTL
+	if previous == nil {
+		if err := s.refreshTokenRepo.CreateRefreshToken(next, ttl); err != nil {
+			return nil, err
+		}
+	} else {
+		rotated, err := s.refreshTokenRepo.RotateRefreshToken(previous, next, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if !rotated {
+			// Lost the race against another refresh with the same token
+			if err := s.refreshTokenRepo.RevokeFamily(familyID); err != nil {
+				return nil, err
+			}
+			return nil, newUnauthorized("refresh token reuse detected, session revoked")
+		}
+	}
+
+	accessToken, err := utils.GenerateToken(user.ExternalID, user.Email, familyID, user.EmailVerifiedAt != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenResponse{
+		Token:        accessToken,
+		RefreshToken: plainRefresh,
+		ExpiresIn:    int(config.AppConfig.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
 // GetProfile retrieves user profile using their external ID from token claims
 func (s *AuthService) GetProfile(externalID string) (*models.User, error) {
 	user, err := s.userRepo.GetUserByExternalID(externalID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+			return nil, newNotFound("user not found")
 		}
 		return nil, err
 	}
@@ -101,11 +409,12 @@ func (s *AuthService) GetProfile(externalID string) (*models.User, error) {
 func (s *AuthService) UpdateProfile(externalID string, req *models.UpdateProfileRequest) (*models.User, error) {
 	user, err := s.userRepo.GetUserByExternalID(externalID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, newNotFound("user not found")
 	}
 
 	user.Name = req.Name
 	user.AvatarURL = req.AvatarURL
+	user.ModifiedBy = &user.ExternalID
 
 	if err := s.userRepo.UpdateUserProfile(user); err != nil {
 		return nil, err
@@ -116,3 +425,127 @@ func (s *AuthService) UpdateProfile(externalID string, req *models.UpdateProfile
 	user.ID = 0
 	return user, nil
 }
+
+// ChangePassword replaces the password after re-checking the current one and signs out every other session
+func (s *AuthService) ChangePassword(externalID, sessionID string, req *models.ChangePasswordRequest) error {
+	user, err := s.userRepo.GetUserByExternalID(externalID)
+	if err != nil {
+		return newNotFound("user not found")
+	}
+
+	if err := utils.CheckPassword(user.Password, req.CurrentPassword); err != nil {
+		return newFieldError("current_password", "current password is incorrect")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	user.ModifiedBy = &user.ExternalID
+
+	if err := s.userRepo.UpdateUserPassword(user); err != nil {
+		return err
+	}
+
+	// Pending reset links were meant for the old password
+	if err := s.userTokenRepo.InvalidateUserTokens(user.ID, models.UserTokenPasswordReset); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeAllForUserExcept(user.ID, sessionID)
+}
+
+// RequestEmailChange emails a confirmation link to the new address; users.email is
+// only updated once that link is used
+func (s *AuthService) RequestEmailChange(externalID string, req *models.ChangeEmailRequest) error {
+	user, err := s.userRepo.GetUserByExternalID(externalID)
+	if err != nil {
+		return newNotFound("user not found")
+	}
+
+	if err := utils.CheckPassword(user.Password, req.CurrentPassword); err != nil {
+		return newFieldError("current_password", "current password is incorrect")
+	}
+
+	if strings.EqualFold(user.Email, req.NewEmail) {
+		return newFieldError("new_email", "new email is the same as the current one")
+	}
+
+	if _, err := s.userRepo.GetUserByEmail(req.NewEmail); err == nil {
+		return newConflict("email already exists")
+	}
+
+	if err := s.userTokenRepo.InvalidateUserTokens(user.ID, models.UserTokenEmailChange); err != nil {
+		return err
+	}
+
+	plainToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	token := &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   models.UserTokenEmailChange,
+		TokenHash: utils.HashToken(plainToken),
+		NewEmail:  &req.NewEmail,
+	}
+	if err := s.userTokenRepo.CreateUserToken(token, config.AppConfig.EmailVerificationTTL); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(&mailer.Message{
+		To:      req.NewEmail,
+		Subject: "Confirm your new NexBoard email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to use this address for your NexBoard account:\n\n%s/confirm-email?token=%s\n\nThe link expires in %s. Until then your current address stays active.\n",
+			user.Name, config.AppConfig.AppBaseURL, plainToken, config.AppConfig.EmailVerificationTTL,
+		),
+	})
+}
+
+// ConfirmEmailChange applies a pending email change and notifies the previous address
+func (s *AuthService) ConfirmEmailChange(plainToken string) (*models.User, error) {
+	token, err := s.consumeUserToken(plainToken, models.UserTokenEmailChange)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(token.UserID)
+	if err != nil {
+		return nil, newNotFound("user not found")
+	}
+
+	// The address may have been taken while the link was pending
+	if _, err := s.userRepo.GetUserByEmail(*token.NewEmail); err == nil {
+		return nil, newConflict("email already exists")
+	}
+
+	previousEmail := user.Email
+	user.Email = *token.NewEmail
+	user.ModifiedBy = &user.ExternalID
+
+	if err := s.userRepo.UpdateUserEmail(user); err != nil {
+		if repositories.IsUniqueViolation(err) {
+			return nil, newConflict("email already exists")
+		}
+		return nil, err
+	}
+
+	notice := &mailer.Message{
+		To:      previousEmail,
+		Subject: "Your NexBoard email address was changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe email address of your NexBoard account was changed to %s. If you did not do this, reset your password right away.\n",
+			user.Name, user.Email,
+		),
+	}
+	if err := s.mailer.Send(notice); err != nil {
+		log.Printf("Failed to send email change notice: %v", err)
+	}
+
+	// Sanitize
+	user.Password = ""
+	user.ID = 0
+	return user, nil
+}
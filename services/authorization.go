@@ -0,0 +1,90 @@
+package services
+
+import (
+	"database/sql"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/repositories"
+)
+
+// workspaceAuthorizer resolves a caller's workspace role and checks it against the policy package,
+// so every service applies the same rules
+type workspaceAuthorizer struct {
+	userRepo      *repositories.UserRepository
+	workspaceRepo *repositories.WorkspaceRepository
+	boardRepo     *repositories.BoardRepository
+	taskRepo      *repositories.TaskRepository
+}
+
+func newWorkspaceAuthorizer(userRepo *repositories.UserRepository, workspaceRepo *repositories.WorkspaceRepository) *workspaceAuthorizer {
+	return &workspaceAuthorizer{userRepo: userRepo, workspaceRepo: workspaceRepo}
+}
+
+// newTaskAuthorizer builds an authorizer that can also resolve tasks, for services acting on a single task
+func newTaskAuthorizer(userRepo *repositories.UserRepository, workspaceRepo *repositories.WorkspaceRepository, boardRepo *repositories.BoardRepository, taskRepo *repositories.TaskRepository) *workspaceAuthorizer {
+	return &workspaceAuthorizer{userRepo: userRepo, workspaceRepo: workspaceRepo, boardRepo: boardRepo, taskRepo: taskRepo}
+}
+
+// authorizeWorkspace loads the caller and workspace and checks the caller may perform the action there
+func (a *workspaceAuthorizer) authorizeWorkspace(userExternalID, workspaceExternalID string, action policy.Action, resource policy.Resource) (*models.User, *models.Workspace, *models.MemberAccess, error) {
+	user, err := a.userRepo.GetUserByExternalID(userExternalID)
+	if err != nil {
+		return nil, nil, nil, newNotFound("user not found")
+	}
+
+	w, err := a.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
+	if err != nil {
+		return nil, nil, nil, newNotFound("workspace not found")
+	}
+
+	access, err := a.authorize(user, w.ID, action, resource)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return user, w, access, nil
+}
+
+// authorizeTask loads the caller, a task and its board, and checks the caller may perform the action on
+// tasks of the board's workspace. The authorizer must come from newTaskAuthorizer.
+func (a *workspaceAuthorizer) authorizeTask(userExternalID, taskExternalID string, action policy.Action) (*models.User, *models.Task, *models.Board, *models.MemberAccess, error) {
+	user, err := a.userRepo.GetUserByExternalID(userExternalID)
+	if err != nil {
+		return nil, nil, nil, nil, newNotFound("user not found")
+	}
+
+	task, err := a.taskRepo.GetTaskByExternalID(taskExternalID)
+	if err != nil {
+		return nil, nil, nil, nil, newNotFound("task not found")
+	}
+
+	board, err := a.boardRepo.GetBoardByID(task.BoardID)
+	if err != nil {
+		return nil, nil, nil, nil, newNotFound("task not found")
+	}
+
+	access, err := a.authorize(user, board.WorkspaceID, action, policy.ResourceTask)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return user, task, board, access, nil
+}
+
+// authorize checks an already loaded user against a workspace and returns their role and permissions
+func (a *workspaceAuthorizer) authorize(user *models.User, workspaceID int, action policy.Action, resource policy.Resource) (*models.MemberAccess, error) {
+	access, err := a.workspaceRepo.GetMemberAccess(workspaceID, user.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newForbidden("not a member of this workspace")
+		}
+		return nil, err
+	}
+
+	if !policy.Can(access.Role, access.Permissions, action, resource) {
+		return nil, newForbidden(policy.DenialMessage(action, resource))
+	}
+
+	return access, nil
+}
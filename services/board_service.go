@@ -1,10 +1,10 @@
 package services
 
 import (
-	"errors"
-
 	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
 	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/storage"
 	"github.com/grahagandangr/nexboard-be/utils"
 )
 
@@ -12,32 +12,29 @@ type BoardService struct {
 	boardRepo     *repositories.BoardRepository
 	workspaceRepo *repositories.WorkspaceRepository
 	userRepo      *repositories.UserRepository
+	blobs         storage.BlobStore
+	authz         *workspaceAuthorizer
 }
 
-func NewBoardService(boardRepo *repositories.BoardRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository) *BoardService {
+func NewBoardService(boardRepo *repositories.BoardRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository, blobs storage.BlobStore) *BoardService {
 	return &BoardService{
 		boardRepo:     boardRepo,
 		workspaceRepo: workspaceRepo,
 		userRepo:      userRepo,
+		blobs:         blobs,
+		authz:         newWorkspaceAuthorizer(userRepo, workspaceRepo),
 	}
 }
 
 // CreateBoard creates a new board in a workspace
 func (s *BoardService) CreateBoard(userExternalID, workspaceExternalID string, req *models.BoardRequest) (*models.BoardResponse, error) {
-	user, err := s.userRepo.GetUserByExternalID(userExternalID)
-	if err != nil {
-		return nil, errors.New("user not found")
-	}
-
-	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
+	user, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionCreate, policy.ResourceBoard)
 	if err != nil {
-		return nil, errors.New("workspace not found")
+		return nil, err
 	}
 
-	// Verify user is a member of the workspace
-	_, err = s.workspaceRepo.GetMemberRole(w.ID, user.ID)
-	if err != nil {
-		return nil, errors.New("unauthorized: not a member of this workspace")
+	if err := validateDisplayName(req.Name); err != nil {
+		return nil, err
 	}
 
 	board := &models.Board{
@@ -64,20 +61,9 @@ func (s *BoardService) CreateBoard(userExternalID, workspaceExternalID string, r
 
 // GetWorkspaceBoards lists all boards in a workspace
 func (s *BoardService) GetWorkspaceBoards(userExternalID, workspaceExternalID string) ([]*models.BoardResponse, error) {
-	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionView, policy.ResourceBoard)
 	if err != nil {
-		return nil, errors.New("user not found")
-	}
-
-	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
-	if err != nil {
-		return nil, errors.New("workspace not found")
-	}
-
-	// Verify user is a member of the workspace
-	_, err = s.workspaceRepo.GetMemberRole(w.ID, user.ID)
-	if err != nil {
-		return nil, errors.New("unauthorized: not a member of this workspace")
+		return nil, err
 	}
 
 	boards, err := s.boardRepo.GetBoardsByWorkspaceID(w.ID)
@@ -102,20 +88,9 @@ func (s *BoardService) GetWorkspaceBoards(userExternalID, workspaceExternalID st
 
 // GetBoard gets detailed info of a board
 func (s *BoardService) GetBoard(userExternalID, boardExternalID string) (*models.BoardResponse, error) {
-	user, err := s.userRepo.GetUserByExternalID(userExternalID)
-	if err != nil {
-		return nil, errors.New("user not found")
-	}
-
-	b, err := s.boardRepo.GetBoardByExternalID(boardExternalID)
+	b, err := s.authorizeBoard(userExternalID, boardExternalID, policy.ActionView)
 	if err != nil {
-		return nil, errors.New("board not found")
-	}
-
-	// Make sure user is member of workspace where this board resides
-	_, err = s.workspaceRepo.GetMemberRole(b.WorkspaceID, user.ID)
-	if err != nil {
-		return nil, errors.New("unauthorized: not a member of the workspace for this board")
+		return nil, err
 	}
 
 	return &models.BoardResponse{
@@ -130,20 +105,13 @@ func (s *BoardService) GetBoard(userExternalID, boardExternalID string) (*models
 
 // UpdateBoard updates a board
 func (s *BoardService) UpdateBoard(userExternalID, boardExternalID string, req *models.BoardRequest) (*models.BoardResponse, error) {
-	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	b, err := s.authorizeBoard(userExternalID, boardExternalID, policy.ActionUpdate)
 	if err != nil {
-		return nil, errors.New("user not found")
-	}
-
-	b, err := s.boardRepo.GetBoardByExternalID(boardExternalID)
-	if err != nil {
-		return nil, errors.New("board not found")
+		return nil, err
 	}
 
-	// Verify user is a member of the workspace
-	_, err = s.workspaceRepo.GetMemberRole(b.WorkspaceID, user.ID)
-	if err != nil {
-		return nil, errors.New("unauthorized: not a member of the workspace")
+	if err := validateDisplayName(req.Name); err != nil {
+		return nil, err
 	}
 
 	b.Name = req.Name
@@ -163,25 +131,36 @@ func (s *BoardService) UpdateBoard(userExternalID, boardExternalID string, req *
 	}, nil
 }
 
-// DeleteBoard deletes a board (and cascades its tasks)
+// DeleteBoard deletes a board (and cascades its tasks and their attachment files), owner/admin only
 func (s *BoardService) DeleteBoard(userExternalID, boardExternalID string) error {
+	b, err := s.authorizeBoard(userExternalID, boardExternalID, policy.ActionDelete)
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.boardRepo.DeleteBoard(b.ID)
+	if err != nil {
+		return err
+	}
+	removeBlobs(s.blobs, keys)
+	return nil
+}
+
+// authorizeBoard loads a board and checks the caller may perform the action on boards of its workspace
+func (s *BoardService) authorizeBoard(userExternalID, boardExternalID string, action policy.Action) (*models.Board, error) {
 	user, err := s.userRepo.GetUserByExternalID(userExternalID)
 	if err != nil {
-		return errors.New("user not found")
+		return nil, newNotFound("user not found")
 	}
 
 	b, err := s.boardRepo.GetBoardByExternalID(boardExternalID)
 	if err != nil {
-		return errors.New("board not found")
+		return nil, newNotFound("board not found")
 	}
 
-	// All workspace members can delete boards currently as per PRD (or maybe just owner/admin).
-	// PRD says: "All workspace members can view boards and tasks". It doesn't restrict Board deletion inherently.
-	// But logically, we check if they are at least a member.
-	_, err = s.workspaceRepo.GetMemberRole(b.WorkspaceID, user.ID)
-	if err != nil {
-		return errors.New("unauthorized: not a member of the workspace")
+	if _, err := s.authz.authorize(user, b.WorkspaceID, action, policy.ResourceBoard); err != nil {
+		return nil, err
 	}
 
-	return s.boardRepo.DeleteBoard(b.ID)
+	return b, nil
 }
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"database/sql"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type ChecklistService struct {
+	checklistRepo *repositories.ChecklistRepository
+	workspaceRepo *repositories.WorkspaceRepository
+	userRepo      *repositories.UserRepository
+	authz         *workspaceAuthorizer
+}
+
+func NewChecklistService(checklistRepo *repositories.ChecklistRepository, taskRepo *repositories.TaskRepository, boardRepo *repositories.BoardRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository) *ChecklistService {
+	return &ChecklistService{
+		checklistRepo: checklistRepo,
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		authz:         newTaskAuthorizer(userRepo, workspaceRepo, boardRepo, taskRepo),
+	}
+}
+
+// CreateItem adds an item to the bottom of a task's checklist
+func (s *ChecklistService) CreateItem(userExternalID, taskExternalID string, req *models.ChecklistItemRequest) (*models.ChecklistItemResponse, error) {
+	user, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	assignedTo, err := resolveWorkspaceAssignee(s.userRepo, s.workspaceRepo, board.WorkspaceID, req.AssignedToExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &models.ChecklistItem{
+		ExternalID: utils.GenerateUUID(),
+		TaskID:     task.ID,
+		Content:    req.Text,
+		IsDone:     req.IsDone,
+		AssignedTo: assignedTo,
+		DueDate:    req.DueDate,
+		CreatedBy:  &user.ExternalID,
+	}
+
+	if err := s.checklistRepo.CreateItem(item); err != nil {
+		return nil, err
+	}
+
+	return s.getItemResponse(task, item.ExternalID)
+}
+
+// GetItems lists a task's checklist in order
+func (s *ChecklistService) GetItems(userExternalID, taskExternalID string) ([]*models.ChecklistItemResponse, error) {
+	_, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.checklistRepo.GetItemsByTaskID(task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapChecklistResponses(items, task.ExternalID), nil
+}
+
+// UpdateItem replaces an item's text, done flag, assignee and due date
+func (s *ChecklistService) UpdateItem(userExternalID, taskExternalID, itemExternalID string, req *models.ChecklistItemRequest) (*models.ChecklistItemResponse, error) {
+	user, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.getItem(task.ID, itemExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignedTo, err := resolveWorkspaceAssignee(s.userRepo, s.workspaceRepo, board.WorkspaceID, req.AssignedToExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Content = req.Text
+	item.IsDone = req.IsDone
+	item.AssignedTo = assignedTo
+	item.DueDate = req.DueDate
+	item.ModifiedBy = &user.ExternalID
+
+	if err := s.checklistRepo.UpdateItem(item); err != nil {
+		return nil, err
+	}
+
+	return s.getItemResponse(task, item.ExternalID)
+}
+
+// DeleteItem removes an item from a task's checklist
+func (s *ChecklistService) DeleteItem(userExternalID, taskExternalID, itemExternalID string) error {
+	_, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return err
+	}
+
+	item, err := s.getItem(task.ID, itemExternalID)
+	if err != nil {
+		return err
+	}
+
+	return s.checklistRepo.DeleteItem(item.ID)
+}
+
+// ReorderItems sets the order of a task's checklist; the request must list every item once
+func (s *ChecklistService) ReorderItems(userExternalID, taskExternalID string, req *models.ReorderChecklistRequest) ([]*models.ChecklistItemResponse, error) {
+	user, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(req.ItemExternalIDs))
+	for _, extID := range req.ItemExternalIDs {
+		if seen[extID] {
+			return nil, newFieldError("item_external_ids", "must not contain duplicates")
+		}
+		seen[extID] = true
+	}
+
+	items, err := s.checklistRepo.ReorderItems(task.ID, req.ItemExternalIDs, user.ExternalID)
+	if err != nil {
+		if err == repositories.ErrChecklistOrderMismatch {
+			return nil, newFieldError("item_external_ids", "must list every checklist item of this task exactly once")
+		}
+		return nil, err
+	}
+
+	return mapChecklistResponses(items, task.ExternalID), nil
+}
+
+func (s *ChecklistService) getItem(taskID int, itemExternalID string) (*models.ChecklistItem, error) {
+	item, err := s.checklistRepo.GetItemByExternalID(taskID, itemExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("checklist item not found")
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// getItemResponse reloads an item so the response carries its assignee's details
+func (s *ChecklistService) getItemResponse(task *models.Task, itemExternalID string) (*models.ChecklistItemResponse, error) {
+	item, err := s.getItem(task.ID, itemExternalID)
+	if err != nil {
+		return nil, err
+	}
+	return mapChecklistResponse(item, task.ExternalID), nil
+}
+
+func mapChecklistResponses(items []*models.ChecklistItem, taskExternalID string) []*models.ChecklistItemResponse {
+	response := []*models.ChecklistItemResponse{}
+	for _, item := range items {
+		response = append(response, mapChecklistResponse(item, taskExternalID))
+	}
+	return response
+}
+
+func mapChecklistResponse(item *models.ChecklistItem, taskExternalID string) *models.ChecklistItemResponse {
+	response := &models.ChecklistItemResponse{
+		ExternalID:     item.ExternalID,
+		TaskExternalID: taskExternalID,
+		Text:           item.Content,
+		IsDone:         item.IsDone,
+		DoneAt:         item.DoneAt,
+		DueDate:        item.DueDate,
+		Position:       item.Position,
+		CreatedAt:      item.CreatedAt,
+		ModifiedAt:     item.ModifiedAt,
+	}
+	if item.AssigneeExternalID != nil {
+		response.AssignedTo = &models.TaskUserInfo{
+			ExternalID: *item.AssigneeExternalID,
+			Name:       *item.AssigneeName,
+		}
+	}
+	return response
+}
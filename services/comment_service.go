@@ -0,0 +1,182 @@
+package services
+
+import (
+	"database/sql"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type CommentService struct {
+	commentRepo   *repositories.TaskCommentRepository
+	workspaceRepo *repositories.WorkspaceRepository
+	authz         *workspaceAuthorizer
+}
+
+func NewCommentService(commentRepo *repositories.TaskCommentRepository, taskRepo *repositories.TaskRepository, boardRepo *repositories.BoardRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository) *CommentService {
+	return &CommentService{
+		commentRepo:   commentRepo,
+		workspaceRepo: workspaceRepo,
+		authz:         newTaskAuthorizer(userRepo, workspaceRepo, boardRepo, taskRepo),
+	}
+}
+
+// CreateComment posts a comment on a task, resolving @mentions of workspace members
+func (s *CommentService) CreateComment(userExternalID, taskExternalID string, req *models.TaskCommentRequest) (*models.TaskCommentResponse, error) {
+	user, task, board, access, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.Can(access.Role, access.Permissions, policy.ActionCreate, policy.ResourceComment) {
+		return nil, newForbidden(policy.DenialMessage(policy.ActionCreate, policy.ResourceComment))
+	}
+
+	mentions, err := s.resolveMentions(board.WorkspaceID, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.TaskComment{
+		ExternalID:       utils.GenerateUUID(),
+		TaskID:           task.ID,
+		AuthorID:         &user.ID,
+		AuthorExternalID: &user.ExternalID,
+		AuthorName:       &user.Name,
+		Body:             req.Body,
+		CreatedBy:        &user.ExternalID,
+		Mentions:         mentions,
+	}
+
+	if err := s.commentRepo.CreateComment(comment); err != nil {
+		return nil, err
+	}
+
+	return mapCommentResponse(comment, task.ExternalID), nil
+}
+
+// GetComments lists a page of a task's comments, newest first
+func (s *CommentService) GetComments(userExternalID, taskExternalID, pageParam, limitParam string) (*models.TaskCommentListResponse, error) {
+	page, limit, err := parsePagination(pageParam, limitParam)
+	if err != nil {
+		return nil, err
+	}
+
+	_, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, total, err := s.commentRepo.GetCommentsByTaskID(task.ID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.TaskCommentListResponse{
+		Comments: []*models.TaskCommentResponse{},
+		Page:     page,
+		Limit:    limit,
+		Total:    total,
+	}
+	for _, c := range comments {
+		response.Comments = append(response.Comments, mapCommentResponse(c, task.ExternalID))
+	}
+	return response, nil
+}
+
+// UpdateComment edits a comment; only its author may do so, and only while their role still allows commenting
+func (s *CommentService) UpdateComment(userExternalID, taskExternalID, commentExternalID string, req *models.TaskCommentRequest) (*models.TaskCommentResponse, error) {
+	user, task, board, access, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.Can(access.Role, access.Permissions, policy.ActionCreate, policy.ResourceComment) {
+		return nil, newForbidden(policy.DenialMessage(policy.ActionCreate, policy.ResourceComment))
+	}
+
+	comment, err := s.getComment(task.ID, commentExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.AuthorID == nil || *comment.AuthorID != user.ID {
+		return nil, newForbidden("only the author can edit a comment")
+	}
+
+	mentions, err := s.resolveMentions(board.WorkspaceID, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.Body = req.Body
+	comment.Mentions = mentions
+	comment.ModifiedBy = &user.ExternalID
+
+	if err := s.commentRepo.UpdateComment(comment); err != nil {
+		return nil, err
+	}
+
+	return mapCommentResponse(comment, task.ExternalID), nil
+}
+
+// DeleteComment removes a comment; authors delete their own, moderators anyone's
+func (s *CommentService) DeleteComment(userExternalID, taskExternalID, commentExternalID string) error {
+	user, task, _, access, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return err
+	}
+
+	comment, err := s.getComment(task.ID, commentExternalID)
+	if err != nil {
+		return err
+	}
+
+	isAuthor := comment.AuthorID != nil && *comment.AuthorID == user.ID
+	if !isAuthor && !policy.Can(access.Role, access.Permissions, policy.ActionModerate, policy.ResourceComment) {
+		return newForbidden(policy.DenialMessage(policy.ActionModerate, policy.ResourceComment))
+	}
+
+	comment.ModifiedBy = &user.ExternalID
+	return s.commentRepo.DeleteComment(comment)
+}
+
+func (s *CommentService) getComment(taskID int, commentExternalID string) (*models.TaskComment, error) {
+	comment, err := s.commentRepo.GetCommentByExternalID(taskID, commentExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("comment not found")
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// resolveMentions matches @name references in a body against the workspace's members
+func (s *CommentService) resolveMentions(workspaceID int, body string) ([]*models.CommentMention, error) {
+	members, err := s.workspaceRepo.GetMembers(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return parseMentions(body, members), nil
+}
+
+func mapCommentResponse(c *models.TaskComment, taskExternalID string) *models.TaskCommentResponse {
+	response := &models.TaskCommentResponse{
+		ExternalID:     c.ExternalID,
+		TaskExternalID: taskExternalID,
+		Body:           c.Body,
+		Mentions:       c.Mentions,
+		CreatedAt:      c.CreatedAt,
+		EditedAt:       c.EditedAt,
+	}
+	if c.AuthorExternalID != nil {
+		response.Author = &models.TaskUserInfo{
+			ExternalID: *c.AuthorExternalID,
+			Name:       *c.AuthorName,
+		}
+	}
+	return response
+}
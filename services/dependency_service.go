@@ -0,0 +1,121 @@
+package services
+
+import (
+	"database/sql"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/repositories"
+)
+
+type DependencyService struct {
+	dependencyRepo *repositories.TaskDependencyRepository
+	taskRepo       *repositories.TaskRepository
+	boardRepo      *repositories.BoardRepository
+	authz          *workspaceAuthorizer
+}
+
+func NewDependencyService(dependencyRepo *repositories.TaskDependencyRepository, taskRepo *repositories.TaskRepository, boardRepo *repositories.BoardRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository) *DependencyService {
+	return &DependencyService{
+		dependencyRepo: dependencyRepo,
+		taskRepo:       taskRepo,
+		boardRepo:      boardRepo,
+		authz:          newTaskAuthorizer(userRepo, workspaceRepo, boardRepo, taskRepo),
+	}
+}
+
+// GetDependencies lists the tasks blocking a task and the tasks it blocks
+func (s *DependencyService) GetDependencies(userExternalID, taskExternalID string) (*models.TaskDependenciesResponse, error) {
+	_, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getDependencies(task)
+}
+
+// AddDependency records that another task of the workspace blocks this one
+func (s *DependencyService) AddDependency(userExternalID, taskExternalID string, req *models.AddDependencyRequest) (*models.TaskDependenciesResponse, error) {
+	user, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	blocker, err := s.resolveBlocker(board, req.BlockerTaskExternalID)
+	if err != nil {
+		return nil, err
+	}
+	if blocker.ID == task.ID {
+		return nil, newFieldError("blocker_task_external_id", "a task cannot block itself")
+	}
+
+	dependency := &models.TaskDependency{
+		BlockerTaskID: blocker.ID,
+		BlockedTaskID: task.ID,
+		CreatedBy:     &user.ExternalID,
+	}
+
+	if err := s.dependencyRepo.AddDependency(dependency, task.BoardID); err != nil {
+		switch {
+		case err == repositories.ErrDependencyCycle:
+			return nil, newFieldError("blocker_task_external_id", "would create a dependency cycle")
+		case err == repositories.ErrDependencyExists:
+			return nil, newConflict("task already blocks this task")
+		case repositories.IsForeignKeyViolation(err):
+			return nil, newNotFound("task not found")
+		}
+		return nil, err
+	}
+
+	return s.getDependencies(task)
+}
+
+// RemoveDependency drops the link between a blocker and this task
+func (s *DependencyService) RemoveDependency(userExternalID, taskExternalID, blockerExternalID string) error {
+	_, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return err
+	}
+
+	blocker, err := s.resolveBlocker(board, blockerExternalID)
+	if err != nil {
+		return newNotFound("dependency not found")
+	}
+
+	if err := s.dependencyRepo.RemoveDependency(blocker.ID, task.ID); err != nil {
+		if err == sql.ErrNoRows {
+			return newNotFound("dependency not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// resolveBlocker loads the other end of a link, which must be a task of the same workspace
+func (s *DependencyService) resolveBlocker(board *models.Board, blockerExternalID string) (*models.Task, error) {
+	blocker, err := s.taskRepo.GetTaskByExternalID(blockerExternalID)
+	if err != nil {
+		return nil, newFieldError("blocker_task_external_id", "task not found in this workspace")
+	}
+
+	blockerBoard, err := s.boardRepo.GetBoardByID(blocker.BoardID)
+	if err != nil || blockerBoard.WorkspaceID != board.WorkspaceID {
+		return nil, newFieldError("blocker_task_external_id", "task not found in this workspace")
+	}
+
+	return blocker, nil
+}
+
+func (s *DependencyService) getDependencies(task *models.Task) (*models.TaskDependenciesResponse, error) {
+	blockedBy, err := s.dependencyRepo.GetBlockers(task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.dependencyRepo.GetDependents(task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TaskDependenciesResponse{BlockedBy: blockedBy, Blocks: blocks}, nil
+}
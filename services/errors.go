@@ -0,0 +1,53 @@
+package services
+
+import "errors"
+
+// Error kinds returned by services, used by handlers to pick the HTTP status
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Error is a domain error with a client-facing message and optional per-field details
+type Error struct {
+	Kind    error
+	Message string
+	Fields  map[string]string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap exposes the kind so callers can use errors.Is(err, ErrNotFound)
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newNotFound(message string) error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+func newForbidden(message string) error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+func newConflict(message string) error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+func newUnauthorized(message string) error {
+	return &Error{Kind: ErrUnauthorized, Message: message}
+}
+
+// newFieldError reports a validation failure on a single request field
+func newFieldError(field, message string) error {
+	return &Error{
+		Kind:    ErrValidation,
+		Message: "invalid " + field,
+		Fields:  map[string]string{field: message},
+	}
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/grahagandangr/nexboard-be/config"
+	"github.com/grahagandangr/nexboard-be/mailer"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type InvitationService struct {
+	invitationRepo *repositories.WorkspaceInvitationRepository
+	workspaceRepo  *repositories.WorkspaceRepository
+	userRepo       *repositories.UserRepository
+	roleRepo       *repositories.WorkspaceRoleRepository
+	authz          *workspaceAuthorizer
+	mailer         mailer.Mailer
+}
+
+func NewInvitationService(invitationRepo *repositories.WorkspaceInvitationRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository, roleRepo *repositories.WorkspaceRoleRepository, mail mailer.Mailer) *InvitationService {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		workspaceRepo:  workspaceRepo,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		authz:          newWorkspaceAuthorizer(userRepo, workspaceRepo),
+		mailer:         mail,
+	}
+}
+
+// CreateInvitation invites an email address (or an existing user) to a workspace (owner/admin only).
+// Registered users get an invitation to accept as well; nobody is added without consent.
+func (s *InvitationService) CreateInvitation(userExternalID, workspaceExternalID string, req *models.InviteMemberRequest) (*models.WorkspaceInvitationResponse, error) {
+	inviter, w, access, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceInvitation)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := resolveGrantableRole(s.roleRepo, w.ID, access, req.Role); err != nil {
+		return nil, err
+	}
+	if !policy.Outranks(access.Role, req.Role) {
+		return nil, newForbidden("cannot invite with a role at or above your own")
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if req.UserExternalID != "" {
+		target, err := s.userRepo.GetUserByExternalID(req.UserExternalID)
+		if err != nil {
+			return nil, newNotFound("target user not found")
+		}
+		email = target.Email
+	}
+	if email == "" {
+		return nil, newFieldError("email", "email or user_external_id is required")
+	}
+
+	// Skip users that are already in the workspace
+	if existing, err := s.userRepo.GetUserByEmail(email); err == nil {
+		if _, err := s.workspaceRepo.GetMemberRole(w.ID, existing.ID); err == nil {
+			return nil, newConflict("user is already a member of this workspace")
+		}
+	}
+
+	plainToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &models.WorkspaceInvitation{
+		ExternalID:          utils.GenerateUUID(),
+		WorkspaceID:         w.ID,
+		WorkspaceExternalID: w.ExternalID,
+		WorkspaceName:       w.Name,
+		Email:               email,
+		Role:                req.Role,
+		TokenHash:           utils.HashToken(plainToken),
+		InvitedByID:         &inviter.ID,
+		InvitedByExternalID: &inviter.ExternalID,
+		InvitedByName:       &inviter.Name,
+		CreatedBy:           &inviter.ExternalID,
+	}
+
+	if err := s.invitationRepo.CreateInvitation(inv, config.AppConfig.InvitationTTL); err != nil {
+		if repositories.IsUniqueViolation(err) {
+			return nil, newConflict("a pending invitation already exists for this email")
+		}
+		return nil, err
+	}
+
+	s.sendInvitationEmail(inv, plainToken)
+
+	return s.mapToResponse(inv), nil
+}
+
+// GetInvitations lists the invitations of a workspace (owner/admin only)
+func (s *InvitationService) GetInvitations(userExternalID, workspaceExternalID string) ([]*models.WorkspaceInvitationResponse, error) {
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceInvitation)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.invitationRepo.GetInvitationsByWorkspaceID(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*models.WorkspaceInvitationResponse
+	for _, inv := range invitations {
+		response = append(response, s.mapToResponse(inv))
+	}
+	return response, nil
+}
+
+// ResendInvitation issues a new link for a pending invitation and restarts its expiry
+func (s *InvitationService) ResendInvitation(userExternalID, workspaceExternalID, invitationExternalID string) (*models.WorkspaceInvitationResponse, error) {
+	inviter, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceInvitation)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.invitationRepo.GetInvitationByExternalID(w.ID, invitationExternalID)
+	if err != nil {
+		return nil, newNotFound("invitation not found")
+	}
+
+	// Expired invitations can be revived, accepted or revoked ones cannot
+	if inv.Status != models.InvitationPending {
+		return nil, newConflict("invitation is no longer pending")
+	}
+
+	plainToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	inv.TokenHash = utils.HashToken(plainToken)
+	inv.ModifiedBy = &inviter.ExternalID
+
+	if err := s.invitationRepo.RenewInvitation(inv, config.AppConfig.InvitationTTL); err != nil {
+		if err == repositories.ErrInvitationNotPending {
+			return nil, newConflict("invitation is no longer pending")
+		}
+		return nil, err
+	}
+	inv.Expired = false
+
+	s.sendInvitationEmail(inv, plainToken)
+
+	return s.mapToResponse(inv), nil
+}
+
+// RevokeInvitation cancels a pending invitation so its link stops working
+func (s *InvitationService) RevokeInvitation(userExternalID, workspaceExternalID, invitationExternalID string) error {
+	inviter, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceInvitation)
+	if err != nil {
+		return err
+	}
+
+	inv, err := s.invitationRepo.GetInvitationByExternalID(w.ID, invitationExternalID)
+	if err != nil {
+		return newNotFound("invitation not found")
+	}
+
+	inv.ModifiedBy = &inviter.ExternalID
+	if err := s.invitationRepo.RevokeInvitation(inv); err != nil {
+		if err == repositories.ErrInvitationNotPending {
+			return newConflict("invitation is no longer pending")
+		}
+		return err
+	}
+	return nil
+}
+
+// PreviewInvitation describes an invitation link to an anonymous visitor so the
+// frontend can offer to log in or to register with the invited address
+func (s *InvitationService) PreviewInvitation(plainToken string) (*models.InvitationPreviewResponse, error) {
+	inv, err := s.invitationRepo.GetInvitationByTokenHash(utils.HashToken(plainToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("invitation not found")
+		}
+		return nil, err
+	}
+
+	_, err = s.userRepo.GetUserByEmail(inv.Email)
+	hasAccount := err == nil
+
+	return &models.InvitationPreviewResponse{
+		WorkspaceExternalID: inv.WorkspaceExternalID,
+		WorkspaceName:       inv.WorkspaceName,
+		InvitedByName:       inv.InvitedByName,
+		Email:               inv.Email,
+		Role:                inv.Role,
+		Status:              effectiveInvitationStatus(inv),
+		ExpiresAt:           inv.ExpiresAt,
+		HasAccount:          hasAccount,
+	}, nil
+}
+
+// AcceptInvitation joins the authenticated user to the inviting workspace.
+// The invitation must have been sent to the user's email address.
+func (s *InvitationService) AcceptInvitation(userExternalID, plainToken string) (*models.WorkspaceResponse, error) {
+	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	if err != nil {
+		return nil, newNotFound("user not found")
+	}
+
+	inv, err := s.invitationRepo.GetInvitationByTokenHash(utils.HashToken(plainToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("invitation not found")
+		}
+		return nil, err
+	}
+
+	if !strings.EqualFold(inv.Email, user.Email) {
+		return nil, newForbidden("this invitation was sent to a different email address")
+	}
+
+	if err := acceptInvitation(s.invitationRepo, s.userRepo, inv, user); err != nil {
+		return nil, err
+	}
+
+	w, err := s.workspaceRepo.GetWorkspaceByExternalID(inv.WorkspaceExternalID)
+	if err != nil {
+		return nil, newNotFound("workspace not found")
+	}
+
+	return &models.WorkspaceResponse{
+		ExternalID:      w.ExternalID,
+		Name:            w.Name,
+		Description:     w.Description,
+		OwnerExternalID: w.OwnerExternalID,
+		CreatedAt:       w.CreatedAt,
+		ModifiedAt:      w.ModifiedAt,
+	}, nil
+}
+
+// acceptInvitation adds the user to the workspace. Receiving the invitation proves
+// the user controls the address, so the email is marked verified as well.
+func acceptInvitation(invitationRepo *repositories.WorkspaceInvitationRepository, userRepo *repositories.UserRepository, inv *models.WorkspaceInvitation, user *models.User) error {
+	if effectiveInvitationStatus(inv) != models.InvitationPending {
+		return newConflict("invitation is no longer pending")
+	}
+
+	if err := invitationRepo.AcceptInvitation(inv, user.ID, user.ExternalID); err != nil {
+		return invitationAcceptError(err)
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return userRepo.MarkEmailVerified(user)
+	}
+	return nil
+}
+
+// invitationAcceptError maps a failed acceptance to the error reported to the user
+func invitationAcceptError(err error) error {
+	if err == repositories.ErrInvitationNotPending {
+		return newConflict("invitation is no longer pending")
+	}
+	if repositories.IsUniqueViolation(err) {
+		return newConflict("user is already a member of this workspace")
+	}
+	if repositories.IsForeignKeyViolation(err) {
+		return newConflict("the role granted by this invitation no longer exists")
+	}
+	return err
+}
+
+// sendInvitationEmail delivers the invitation link; failures are logged since the invite can be resent
+func (s *InvitationService) sendInvitationEmail(inv *models.WorkspaceInvitation, plainToken string) {
+	inviterName := "A teammate"
+	if inv.InvitedByName != nil {
+		inviterName = *inv.InvitedByName
+	}
+
+	msg := &mailer.Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("You have been invited to %s on NexBoard", inv.WorkspaceName),
+		Body: fmt.Sprintf(
+			"Hi,\n\n%s invited you to join the workspace \"%s\" as %s.\n\nOpen the link below to accept. If you do not have an account yet, you can create one with this email address:\n\n%s/invitations/accept?token=%s\n\nThe invitation expires on %s.\n",
+			inviterName, inv.WorkspaceName, inv.Role, config.AppConfig.AppBaseURL, plainToken, inv.ExpiresAt.Format("2006-01-02 15:04 MST"),
+		),
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("Failed to send invitation email: %v", err)
+	}
+}
+
+// Helper mapper
+func (s *InvitationService) mapToResponse(inv *models.WorkspaceInvitation) *models.WorkspaceInvitationResponse {
+	return &models.WorkspaceInvitationResponse{
+		ExternalID:          inv.ExternalID,
+		WorkspaceExternalID: inv.WorkspaceExternalID,
+		Email:               inv.Email,
+		Role:                inv.Role,
+		Status:              effectiveInvitationStatus(inv),
+		InvitedByExternalID: inv.InvitedByExternalID,
+		InvitedByName:       inv.InvitedByName,
+		ExpiresAt:           inv.ExpiresAt,
+		AcceptedAt:          inv.AcceptedAt,
+		CreatedAt:           inv.CreatedAt,
+	}
+}
+
+// effectiveInvitationStatus reports pending invitations past their expiry as expired
+func effectiveInvitationStatus(inv *models.WorkspaceInvitation) string {
+	if inv.Status == models.InvitationPending && inv.Expired {
+		return models.InvitationExpired
+	}
+	return inv.Status
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/grahagandangr/nexboard-be/config"
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type JoinLinkService struct {
+	joinLinkRepo  *repositories.WorkspaceJoinLinkRepository
+	workspaceRepo *repositories.WorkspaceRepository
+	userRepo      *repositories.UserRepository
+	roleRepo      *repositories.WorkspaceRoleRepository
+	authz         *workspaceAuthorizer
+}
+
+func NewJoinLinkService(joinLinkRepo *repositories.WorkspaceJoinLinkRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository, roleRepo *repositories.WorkspaceRoleRepository) *JoinLinkService {
+	return &JoinLinkService{
+		joinLinkRepo:  joinLinkRepo,
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		authz:         newWorkspaceAuthorizer(userRepo, workspaceRepo),
+	}
+}
+
+// CreateJoinLink makes a shareable link that adds whoever opens it to the workspace (owner/admin only)
+func (s *JoinLinkService) CreateJoinLink(userExternalID, workspaceExternalID string, req *models.CreateJoinLinkRequest) (*models.WorkspaceJoinLinkResponse, error) {
+	creator, w, access, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceJoinLink)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := resolveGrantableRole(s.roleRepo, w.ID, access, req.Role); err != nil {
+		return nil, err
+	}
+	if !policy.Outranks(access.Role, req.Role) {
+		return nil, newForbidden("cannot create links with a role at or above your own")
+	}
+
+	code, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := config.AppConfig.JoinLinkTTL
+	if req.ExpiresInHours != nil {
+		ttl = time.Duration(*req.ExpiresInHours) * time.Hour
+	}
+
+	link := &models.WorkspaceJoinLink{
+		ExternalID:          utils.GenerateUUID(),
+		WorkspaceID:         w.ID,
+		WorkspaceExternalID: w.ExternalID,
+		WorkspaceName:       w.Name,
+		Code:                code,
+		Role:                req.Role,
+		MaxUses:             req.MaxUses,
+		CreatedByID:         &creator.ID,
+		CreatedByExternalID: &creator.ExternalID,
+		CreatedBy:           &creator.ExternalID,
+	}
+
+	if err := s.joinLinkRepo.CreateJoinLink(link, ttl); err != nil {
+		return nil, err
+	}
+
+	return s.mapToResponse(link), nil
+}
+
+// GetJoinLinks lists the join links of a workspace (owner/admin only)
+func (s *JoinLinkService) GetJoinLinks(userExternalID, workspaceExternalID string) ([]*models.WorkspaceJoinLinkResponse, error) {
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceJoinLink)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.joinLinkRepo.GetJoinLinksByWorkspaceID(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*models.WorkspaceJoinLinkResponse
+	for _, link := range links {
+		response = append(response, s.mapToResponse(link))
+	}
+	return response, nil
+}
+
+// GetJoinLinkUses lists who joined through a link (owner/admin only)
+func (s *JoinLinkService) GetJoinLinkUses(userExternalID, workspaceExternalID, linkExternalID string) ([]*models.JoinLinkUseResponse, error) {
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceJoinLink)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.joinLinkRepo.GetJoinLinkByExternalID(w.ID, linkExternalID)
+	if err != nil {
+		return nil, newNotFound("join link not found")
+	}
+
+	return s.joinLinkRepo.GetJoinLinkUses(link.ID)
+}
+
+// RevokeJoinLink stops a link from admitting anyone else; existing members are unaffected
+func (s *JoinLinkService) RevokeJoinLink(userExternalID, workspaceExternalID, linkExternalID string) (*models.WorkspaceJoinLinkResponse, error) {
+	user, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceJoinLink)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.joinLinkRepo.GetJoinLinkByExternalID(w.ID, linkExternalID)
+	if err != nil {
+		return nil, newNotFound("join link not found")
+	}
+
+	link.ModifiedBy = &user.ExternalID
+	if err := s.joinLinkRepo.RevokeJoinLink(link); err != nil {
+		return nil, err
+	}
+
+	return s.mapToResponse(link), nil
+}
+
+// PreviewJoinLink tells a user which workspace a link leads to before they join
+func (s *JoinLinkService) PreviewJoinLink(code string) (*models.JoinLinkPreviewResponse, error) {
+	link, err := s.getJoinLinkByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.JoinLinkPreviewResponse{
+		WorkspaceExternalID: link.WorkspaceExternalID,
+		WorkspaceName:       link.WorkspaceName,
+		Role:                link.Role,
+		Status:              joinLinkStatus(link),
+		ExpiresAt:           link.ExpiresAt,
+	}, nil
+}
+
+// JoinWorkspace adds the authenticated user to the link's workspace with the link's role
+func (s *JoinLinkService) JoinWorkspace(userExternalID, code string) (*models.WorkspaceResponse, error) {
+	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	if err != nil {
+		return nil, newNotFound("user not found")
+	}
+
+	link, err := s.getJoinLinkByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Existing members must not burn a use
+	if _, err := s.workspaceRepo.GetMemberRole(link.WorkspaceID, user.ID); err == nil {
+		return nil, newConflict("user is already a member of this workspace")
+	}
+
+	if status := joinLinkStatus(link); status != models.JoinLinkActive {
+		return nil, newConflict(fmt.Sprintf("join link is %s", status))
+	}
+
+	member := &models.WorkspaceMember{
+		WorkspaceID: link.WorkspaceID,
+		UserID:      user.ID,
+		Role:        link.Role,
+		CreatedBy:   &user.ExternalID,
+	}
+	if err := s.joinLinkRepo.JoinWithLink(link, member); err != nil {
+		if err == repositories.ErrJoinLinkInactive {
+			return nil, newConflict("join link is no longer active")
+		}
+		if repositories.IsUniqueViolation(err) {
+			return nil, newConflict("user is already a member of this workspace")
+		}
+		if repositories.IsForeignKeyViolation(err) {
+			return nil, newConflict("the role granted by this link no longer exists")
+		}
+		return nil, err
+	}
+
+	w, err := s.workspaceRepo.GetWorkspaceByExternalID(link.WorkspaceExternalID)
+	if err != nil {
+		return nil, newNotFound("workspace not found")
+	}
+
+	return &models.WorkspaceResponse{
+		ExternalID:      w.ExternalID,
+		Name:            w.Name,
+		Description:     w.Description,
+		OwnerExternalID: w.OwnerExternalID,
+		CreatedAt:       w.CreatedAt,
+		ModifiedAt:      w.ModifiedAt,
+	}, nil
+}
+
+// getJoinLinkByCode resolves a shareable code, hiding links of deleted workspaces
+func (s *JoinLinkService) getJoinLinkByCode(code string) (*models.WorkspaceJoinLink, error) {
+	link, err := s.joinLinkRepo.GetJoinLinkByCode(code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("join link not found")
+		}
+		return nil, err
+	}
+	return link, nil
+}
+
+// Helper mapper
+func (s *JoinLinkService) mapToResponse(link *models.WorkspaceJoinLink) *models.WorkspaceJoinLinkResponse {
+	return &models.WorkspaceJoinLinkResponse{
+		ExternalID:          link.ExternalID,
+		WorkspaceExternalID: link.WorkspaceExternalID,
+		Code:                link.Code,
+		URL:                 fmt.Sprintf("%s/join/%s", config.AppConfig.AppBaseURL, link.Code),
+		Role:                link.Role,
+		MaxUses:             link.MaxUses,
+		UseCount:            link.UseCount,
+		Status:              joinLinkStatus(link),
+		ExpiresAt:           link.ExpiresAt,
+		RevokedAt:           link.RevokedAt,
+		CreatedByExternalID: link.CreatedByExternalID,
+		CreatedAt:           link.CreatedAt,
+	}
+}
+
+// joinLinkStatus derives the state of a link; revocation wins over expiry and exhaustion
+func joinLinkStatus(link *models.WorkspaceJoinLink) string {
+	switch {
+	case link.RevokedAt != nil:
+		return models.JoinLinkRevoked
+	case link.Expired:
+		return models.JoinLinkExpired
+	case link.MaxUses != nil && link.UseCount >= *link.MaxUses:
+		return models.JoinLinkExhausted
+	default:
+		return models.JoinLinkActive
+	}
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+type LabelService struct {
+	labelRepo *repositories.LabelRepository
+	taskRepo  *repositories.TaskRepository
+	authz     *workspaceAuthorizer
+}
+
+func NewLabelService(labelRepo *repositories.LabelRepository, taskRepo *repositories.TaskRepository, boardRepo *repositories.BoardRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository) *LabelService {
+	return &LabelService{
+		labelRepo: labelRepo,
+		taskRepo:  taskRepo,
+		authz:     newTaskAuthorizer(userRepo, workspaceRepo, boardRepo, taskRepo),
+	}
+}
+
+// GetLabels lists a workspace's labels by name
+func (s *LabelService) GetLabels(userExternalID, workspaceExternalID string) ([]*models.LabelResponse, error) {
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionView, policy.ResourceBoard)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := s.labelRepo.GetLabelsByWorkspaceID(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []*models.LabelResponse{}
+	for _, l := range labels {
+		response = append(response, mapLabelResponse(l, w.ExternalID))
+	}
+	return response, nil
+}
+
+// CreateLabel adds a label to a workspace
+func (s *LabelService) CreateLabel(userExternalID, workspaceExternalID string, req *models.LabelRequest) (*models.LabelResponse, error) {
+	user, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, newFieldError("name", "name is required")
+	}
+
+	label := &models.Label{
+		ExternalID:  utils.GenerateUUID(),
+		WorkspaceID: w.ID,
+		Name:        name,
+		Color:       req.Color,
+		CreatedBy:   &user.ExternalID,
+	}
+
+	if err := s.labelRepo.CreateLabel(label); err != nil {
+		if repositories.IsUniqueViolation(err) {
+			return nil, newConflict("a label with this name already exists")
+		}
+		return nil, err
+	}
+
+	return mapLabelResponse(label, w.ExternalID), nil
+}
+
+// UpdateLabel renames or recolors a label; tasks carrying it pick up the change
+func (s *LabelService) UpdateLabel(userExternalID, workspaceExternalID, labelExternalID string, req *models.LabelRequest) (*models.LabelResponse, error) {
+	user, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	label, err := s.getLabel(w.ID, labelExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, newFieldError("name", "name is required")
+	}
+
+	label.Name = name
+	label.Color = req.Color
+	label.ModifiedBy = &user.ExternalID
+
+	if err := s.labelRepo.UpdateLabel(label); err != nil {
+		if repositories.IsUniqueViolation(err) {
+			return nil, newConflict("a label with this name already exists")
+		}
+		return nil, err
+	}
+
+	return mapLabelResponse(label, w.ExternalID), nil
+}
+
+// DeleteLabel removes a label from the workspace and from every task carrying it
+func (s *LabelService) DeleteLabel(userExternalID, workspaceExternalID, labelExternalID string) error {
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceLabel)
+	if err != nil {
+		return err
+	}
+
+	label, err := s.getLabel(w.ID, labelExternalID)
+	if err != nil {
+		return err
+	}
+
+	return s.labelRepo.DeleteLabel(label.ID)
+}
+
+// AddTaskLabel attaches a label of the task's workspace to the task
+func (s *LabelService) AddTaskLabel(userExternalID, taskExternalID string, req *models.TaskLabelRequest) (*models.TaskResponse, error) {
+	user, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	label, err := s.labelRepo.GetLabelByExternalID(board.WorkspaceID, req.LabelExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newFieldError("label_external_id", "label not found in this workspace")
+		}
+		return nil, err
+	}
+
+	if err := s.labelRepo.AddTaskLabel(task.ID, label.ID, user.ExternalID); err != nil {
+		if repositories.IsForeignKeyViolation(err) {
+			return nil, newNotFound("task or label not found")
+		}
+		return nil, err
+	}
+
+	return s.getTaskResponse(taskExternalID)
+}
+
+// RemoveTaskLabel detaches a label from a task
+func (s *LabelService) RemoveTaskLabel(userExternalID, taskExternalID, labelExternalID string) (*models.TaskResponse, error) {
+	_, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	label, err := s.getLabel(board.WorkspaceID, labelExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.labelRepo.RemoveTaskLabel(task.ID, label.ID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("label is not on this task")
+		}
+		return nil, err
+	}
+
+	return s.getTaskResponse(taskExternalID)
+}
+
+func (s *LabelService) getLabel(workspaceID int, labelExternalID string) (*models.Label, error) {
+	label, err := s.labelRepo.GetLabelByExternalID(workspaceID, labelExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("label not found")
+		}
+		return nil, err
+	}
+	return label, nil
+}
+
+func (s *LabelService) getTaskResponse(taskExternalID string) (*models.TaskResponse, error) {
+	tr, err := s.taskRepo.GetTaskResponseByExternalID(taskExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("task not found")
+		}
+		return nil, err
+	}
+	return tr, nil
+}
+
+func mapLabelResponse(l *models.Label, workspaceExternalID string) *models.LabelResponse {
+	return &models.LabelResponse{
+		ExternalID:          l.ExternalID,
+		WorkspaceExternalID: workspaceExternalID,
+		Name:                l.Name,
+		Color:               l.Color,
+		CreatedAt:           l.CreatedAt,
+		ModifiedAt:          l.ModifiedAt,
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grahagandangr/nexboard-be/models"
+)
+
+// parseMentions finds @name references to workspace members in a comment body. A member can be
+// mentioned by display name ("@Bob Programmer") or by the local part of their email ("@bob");
+// the longest match wins, so "@Bob Programmer" is not read as a mention of a member named "Bob".
+// Each member is returned once, in order of first mention.
+func parseMentions(body string, members []*models.WorkspaceMemberResponse) []*models.CommentMention {
+	mentions := []*models.CommentMention{}
+	seen := make(map[string]bool)
+
+	for i := 0; i < len(body); i++ {
+		if body[i] != '@' || (i > 0 && isMentionRune(lastRune(body[:i]))) {
+			// Skip email addresses such as bob@example.com
+			continue
+		}
+		rest := body[i+1:]
+
+		var match *models.WorkspaceMemberResponse
+		matchLen := 0
+		for _, m := range members {
+			for _, handle := range mentionHandles(m) {
+				if len(handle) > matchLen && hasMentionPrefix(rest, handle) {
+					match, matchLen = m, len(handle)
+				}
+			}
+		}
+		if match == nil {
+			continue
+		}
+
+		if !seen[match.UserExternalID] {
+			seen[match.UserExternalID] = true
+			mentions = append(mentions, &models.CommentMention{
+				UserExternalID: match.UserExternalID,
+				Name:           match.Name,
+				Text:           body[i : i+1+matchLen],
+			})
+		}
+		i += matchLen
+	}
+
+	return mentions
+}
+
+// mentionHandles lists the ways a member can be mentioned
+func mentionHandles(m *models.WorkspaceMemberResponse) []string {
+	handles := []string{m.Name}
+	if local, _, ok := strings.Cut(m.Email, "@"); ok && local != "" {
+		handles = append(handles, local)
+	}
+	return handles
+}
+
+// hasMentionPrefix reports whether s starts with handle, ignoring case, and the handle ends on a word boundary
+func hasMentionPrefix(s, handle string) bool {
+	if handle == "" || len(s) < len(handle) || !strings.EqualFold(s[:len(handle)], handle) {
+		return false
+	}
+	if len(s) == len(handle) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(s[len(handle):])
+	return !isMentionRune(r)
+}
+
+func isMentionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// validateDisplayName rejects names with control characters. Workspace and board names end up
+// in email subjects and other single-line contexts, so line breaks must never get through.
+func validateDisplayName(name string) error {
+	if strings.IndexFunc(name, unicode.IsControl) != -1 {
+		return newFieldError("name", "name cannot contain control characters")
+	}
+	return nil
+}
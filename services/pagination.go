@@ -0,0 +1,26 @@
+package services
+
+import "strconv"
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// parsePagination reads optional ?page= and ?limit= values, defaulting to the first page of 20 items
+func parsePagination(pageParam, limitParam string) (page, limit int, err error) {
+	page, limit = 1, defaultPageLimit
+
+	if pageParam != "" {
+		if page, err = strconv.Atoi(pageParam); err != nil || page < 1 {
+			return 0, 0, newFieldError("page", "must be a positive integer")
+		}
+	}
+	if limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 || limit > maxPageLimit {
+			return 0, 0, newFieldError("limit", "must be between 1 and "+strconv.Itoa(maxPageLimit))
+		}
+	}
+
+	return page, limit, nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
+	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/utils"
+)
+
+// builtinRoleDescriptions describes the roles seeded into every workspace
+var builtinRoleDescriptions = map[string]string{
+	models.RoleOwner:  "Full control of the workspace",
+	models.RoleAdmin:  "Manages members, boards and tasks",
+	models.RoleMember: "Works on boards and tasks",
+}
+
+type RoleService struct {
+	roleRepo      *repositories.WorkspaceRoleRepository
+	workspaceRepo *repositories.WorkspaceRepository
+	userRepo      *repositories.UserRepository
+	authz         *workspaceAuthorizer
+}
+
+func NewRoleService(roleRepo *repositories.WorkspaceRoleRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository) *RoleService {
+	return &RoleService{
+		roleRepo:      roleRepo,
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		authz:         newWorkspaceAuthorizer(userRepo, workspaceRepo),
+	}
+}
+
+// GetRoles lists the roles of a workspace
+func (s *RoleService) GetRoles(userExternalID, workspaceExternalID string) ([]*models.WorkspaceRoleResponse, error) {
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionView, policy.ResourceRole)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.GetRolesByWorkspaceID(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*models.WorkspaceRoleResponse
+	for _, role := range roles {
+		response = append(response, s.mapToResponse(role))
+	}
+	return response, nil
+}
+
+// CreateRole adds a custom role. Custom roles rank with members in the role hierarchy.
+func (s *RoleService) CreateRole(userExternalID, workspaceExternalID string, req *models.WorkspaceRoleRequest) (*models.WorkspaceRoleResponse, error) {
+	user, w, access, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceRole)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, newFieldError("name", "name is required")
+	}
+
+	permissions, err := validatePermissions(access, name, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.WorkspaceRole{
+		ExternalID:  utils.GenerateUUID(),
+		WorkspaceID: w.ID,
+		Name:        name,
+		Description: req.Description,
+		Permissions: permissions,
+		CreatedBy:   &user.ExternalID,
+	}
+
+	if err := s.roleRepo.CreateRole(role); err != nil {
+		if repositories.IsUniqueViolation(err) {
+			return nil, newConflict("a role with this name already exists")
+		}
+		return nil, err
+	}
+
+	return s.mapToResponse(role), nil
+}
+
+// UpdateRole replaces a role's name, description and permissions. Built-in roles keep their
+// names, the owner role cannot be changed at all, and callers only edit roles below their own.
+func (s *RoleService) UpdateRole(userExternalID, workspaceExternalID, roleExternalID string, req *models.WorkspaceRoleRequest) (*models.WorkspaceRoleResponse, error) {
+	user, w, access, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceRole)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.GetRoleByExternalID(w.ID, roleExternalID)
+	if err != nil {
+		return nil, newNotFound("role not found")
+	}
+
+	if role.Name == models.RoleOwner {
+		return nil, newForbidden("the owner role cannot be changed")
+	}
+	if !policy.Outranks(access.Role, role.Name) {
+		return nil, newForbidden("cannot change roles at or above your own")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, newFieldError("name", "name is required")
+	}
+	if role.IsBuiltin && name != role.Name {
+		return nil, newFieldError("name", "built-in roles cannot be renamed")
+	}
+
+	permissions, err := validatePermissions(access, name, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	previousName := role.Name
+	role.Name = name
+	role.Description = req.Description
+	role.Permissions = permissions
+	role.ModifiedBy = &user.ExternalID
+
+	if err := s.roleRepo.UpdateRole(role, previousName); err != nil {
+		if repositories.IsUniqueViolation(err) {
+			return nil, newConflict("a role with this name already exists")
+		}
+		return nil, err
+	}
+
+	return s.mapToResponse(role), nil
+}
+
+// DeleteRole removes a custom role that nobody holds or is about to be granted
+func (s *RoleService) DeleteRole(userExternalID, workspaceExternalID, roleExternalID string) error {
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionManage, policy.ResourceRole)
+	if err != nil {
+		return err
+	}
+
+	role, err := s.roleRepo.GetRoleByExternalID(w.ID, roleExternalID)
+	if err != nil {
+		return newNotFound("role not found")
+	}
+
+	if role.IsBuiltin {
+		return newForbidden("built-in roles cannot be deleted")
+	}
+
+	if role.MemberCount > 0 {
+		return newConflict("role is still assigned to members")
+	}
+
+	pending, err := s.roleRepo.CountPendingRoleGrants(w.ID, role.Name)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return newConflict("role is still granted by pending invitations or join links")
+	}
+
+	if err := s.roleRepo.DeleteRole(role.ID); err != nil {
+		if repositories.IsForeignKeyViolation(err) {
+			return newConflict("role is still assigned to members")
+		}
+		return err
+	}
+	return nil
+}
+
+// Helper mapper
+func (s *RoleService) mapToResponse(role *models.WorkspaceRole) *models.WorkspaceRoleResponse {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &models.WorkspaceRoleResponse{
+		ExternalID:  role.ExternalID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		IsBuiltin:   role.IsBuiltin,
+		MemberCount: role.MemberCount,
+		CreatedAt:   role.CreatedAt,
+		ModifiedAt:  role.ModifiedAt,
+	}
+}
+
+// validatePermissions rejects unknown permissions, ones the role's rank cannot use and, to prevent
+// escalation, any the caller does not hold themselves. Duplicates are dropped.
+func validatePermissions(access *models.MemberAccess, roleName string, requested []string) ([]string, error) {
+	permissions := []string{}
+	seen := map[string]bool{}
+	for _, p := range requested {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		if !policy.IsPermission(p) {
+			return nil, newFieldError("permissions", "unknown permission "+p)
+		}
+		if !policy.CanHold(roleName, policy.Permission(p)) {
+			return nil, newFieldError("permissions", p+" has no effect on roles ranked with members")
+		}
+		if err := checkPermissionHeld(access, p); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+	return permissions, nil
+}
+
+// checkPermissionHeld refuses to pass on a permission the caller does not hold
+func checkPermissionHeld(access *models.MemberAccess, p string) error {
+	resource, action, _ := strings.Cut(p, ":")
+	if !policy.Can(access.Role, access.Permissions, policy.Action(action), policy.Resource(resource)) {
+		return newForbidden("cannot grant a permission you do not have: " + p)
+	}
+	return nil
+}
+
+// resolveGrantableRole checks that a role exists in the workspace and may be handed out directly
+// by the caller, which requires holding every permission the role carries
+func resolveGrantableRole(roleRepo *repositories.WorkspaceRoleRepository, workspaceID int, access *models.MemberAccess, name string) (*models.WorkspaceRole, error) {
+	if name == models.RoleOwner {
+		return nil, newFieldError("role", "owner can only be assigned by transferring ownership")
+	}
+
+	role, err := roleRepo.GetRoleByName(workspaceID, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newFieldError("role", "role does not exist in this workspace")
+		}
+		return nil, err
+	}
+
+	for _, p := range role.Permissions {
+		if err := checkPermissionHeld(access, p); err != nil {
+			return nil, err
+		}
+	}
+	return role, nil
+}
+
+// builtinRoles builds the roles a new workspace is seeded with
+func builtinRoles(createdBy string) []*models.WorkspaceRole {
+	var roles []*models.WorkspaceRole
+	for _, name := range policy.BuiltinRoles {
+		description := builtinRoleDescriptions[name]
+		var permissions []string
+		for _, p := range policy.DefaultRolePermissions[name] {
+			permissions = append(permissions, string(p))
+		}
+		roles = append(roles, &models.WorkspaceRole{
+			ExternalID:  utils.GenerateUUID(),
+			Name:        name,
+			Description: &description,
+			Permissions: permissions,
+			IsBuiltin:   true,
+			CreatedBy:   &createdBy,
+		})
+	}
+	return roles
+}
@@ -2,130 +2,426 @@ package services
 
 import (
 	"database/sql"
-	"errors"
 
 	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
 	"github.com/grahagandangr/nexboard-be/repositories"
 	"github.com/grahagandangr/nexboard-be/utils"
 )
 
+// defaultStatusSet is the status set every new workspace starts with
+var defaultStatusSet = []struct {
+	Name     string
+	Color    string
+	Category string
+}{
+	{"To Do", "#94a3b8", models.StatusCategoryTodo},
+	{"In Progress", "#3b82f6", models.StatusCategoryInProgress},
+	{"Done", "#22c55e", models.StatusCategoryDone},
+}
+
 type StatusService struct {
-	statusRepo *repositories.StatusRepository
+	statusRepo    *repositories.StatusRepository
+	boardRepo     *repositories.BoardRepository
+	workspaceRepo *repositories.WorkspaceRepository
+	userRepo      *repositories.UserRepository
+	authz         *workspaceAuthorizer
 }
 
-func NewStatusService(statusRepo *repositories.StatusRepository) *StatusService {
-	return &StatusService{statusRepo: statusRepo}
+func NewStatusService(statusRepo *repositories.StatusRepository, boardRepo *repositories.BoardRepository, workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository) *StatusService {
+	return &StatusService{
+		statusRepo:    statusRepo,
+		boardRepo:     boardRepo,
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		authz:         newWorkspaceAuthorizer(userRepo, workspaceRepo),
+	}
 }
 
-// CreateStatus creates new master status
-func (s *StatusService) CreateStatus(req *models.StatusRequest) (*models.StatusResponse, error) {
-	// Check name uniqueness
-	if _, err := s.statusRepo.GetStatusByName(req.Name); err == nil {
-		return nil, errors.New("a status with this name already exists")
+// CreateWorkspaceStatus adds a status available on every board of the workspace
+func (s *StatusService) CreateWorkspaceStatus(userExternalID, workspaceExternalID string, req *models.StatusRequest) (*models.StatusResponse, error) {
+	user, w, err := s.authorizeWorkspaceManagement(userExternalID, workspaceExternalID)
+	if err != nil {
+		return nil, err
 	}
 
-	pos := 0
-	if req.Position != nil {
-		pos = *req.Position
+	status := &models.Status{
+		WorkspaceID:         w.ID,
+		WorkspaceExternalID: w.ExternalID,
+	}
+	return s.createStatus(user, status, req)
+}
+
+// GetWorkspaceStatuses lists the statuses shared by every board of the workspace
+func (s *StatusService) GetWorkspaceStatuses(userExternalID, workspaceExternalID string) ([]*models.StatusResponse, error) {
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionView, policy.ResourceBoard)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.statusRepo.GetStatusesByWorkspaceID(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapToResponses(statuses), nil
+}
+
+// CreateBoardStatus adds a status only available on one board
+func (s *StatusService) CreateBoardStatus(userExternalID, boardExternalID string, req *models.StatusRequest) (*models.StatusResponse, error) {
+	user, b, err := s.resolveBoard(userExternalID, boardExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeManagement(user, b.WorkspaceID); err != nil {
+		return nil, err
 	}
 
 	status := &models.Status{
-		ExternalID: utils.GenerateUUID(),
-		Name:       req.Name,
-		Color:      req.Color,
-		Position:   pos,
+		WorkspaceID:         b.WorkspaceID,
+		WorkspaceExternalID: b.WorkspaceExternalID,
+		BoardID:             &b.ID,
+		BoardExternalID:     &b.ExternalID,
 	}
+	return s.createStatus(user, status, req)
+}
 
-	if err := s.statusRepo.CreateStatus(status); err != nil {
+// GetBoardStatuses lists every status tasks on the board can use, workspace-wide ones included
+func (s *StatusService) GetBoardStatuses(userExternalID, boardExternalID string) ([]*models.StatusResponse, error) {
+	user, b, err := s.resolveBoard(userExternalID, boardExternalID)
+	if err != nil {
 		return nil, err
 	}
 
-	return s.mapToResponse(status), nil
+	if _, err := s.authz.authorize(user, b.WorkspaceID, policy.ActionView, policy.ResourceBoard); err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.statusRepo.GetStatusesByBoardID(b.ID, b.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapToResponses(statuses), nil
 }
 
-// GetAllStatuses retrieves all
-func (s *StatusService) GetAllStatuses() ([]*models.StatusResponse, error) {
-	statuses, err := s.statusRepo.GetAllStatuses()
+// ReorderWorkspaceStatuses renumbers the workspace-wide statuses to follow the given order
+func (s *StatusService) ReorderWorkspaceStatuses(userExternalID, workspaceExternalID string, req *models.ReorderStatusesRequest) ([]*models.StatusResponse, error) {
+	user, w, err := s.authorizeWorkspaceManagement(userExternalID, workspaceExternalID)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []*models.StatusResponse
-	for _, st := range statuses {
-		response = append(response, s.mapToResponse(st))
+	return s.reorderStatuses(user, w.ID, nil, req)
+}
+
+// ReorderBoardStatuses renumbers a board's own statuses to follow the given order
+func (s *StatusService) ReorderBoardStatuses(userExternalID, boardExternalID string, req *models.ReorderStatusesRequest) ([]*models.StatusResponse, error) {
+	user, b, err := s.resolveBoard(userExternalID, boardExternalID)
+	if err != nil {
+		return nil, err
 	}
 
-	return response, nil
+	if err := s.authorizeManagement(user, b.WorkspaceID); err != nil {
+		return nil, err
+	}
+
+	return s.reorderStatuses(user, b.WorkspaceID, &b.ID, req)
 }
 
-// GetStatus retrieves detail
-func (s *StatusService) GetStatus(externalID string) (*models.StatusResponse, error) {
-	status, err := s.statusRepo.GetStatusByExternalID(externalID)
+// reorderStatuses applies a complete column order to one scope in a single transaction
+func (s *StatusService) reorderStatuses(user *models.User, workspaceID int, boardID *int, req *models.ReorderStatusesRequest) ([]*models.StatusResponse, error) {
+	seen := make(map[string]bool, len(req.StatusExternalIDs))
+	for _, extID := range req.StatusExternalIDs {
+		if seen[extID] {
+			return nil, newFieldError("status_external_ids", "must not contain duplicates")
+		}
+		seen[extID] = true
+	}
+
+	statuses, err := s.statusRepo.ReorderStatuses(workspaceID, boardID, req.StatusExternalIDs, user.ID, user.ExternalID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("status not found")
+		if err == repositories.ErrStatusOrderMismatch {
+			return nil, newFieldError("status_external_ids", "must list every status of this scope exactly once")
 		}
 		return nil, err
 	}
 
+	return s.mapToResponses(statuses), nil
+}
+
+// GetStatus retrieves detail
+func (s *StatusService) GetStatus(userExternalID, externalID string) (*models.StatusResponse, error) {
+	user, status, err := s.resolveStatus(userExternalID, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.authz.authorize(user, status.WorkspaceID, policy.ActionView, policy.ResourceBoard); err != nil {
+		return nil, err
+	}
+
 	return s.mapToResponse(status), nil
 }
 
 // UpdateStatus modifies status detail
-func (s *StatusService) UpdateStatus(externalID string, req *models.StatusRequest) (*models.StatusResponse, error) {
-	status, err := s.statusRepo.GetStatusByExternalID(externalID)
+func (s *StatusService) UpdateStatus(userExternalID, externalID string, req *models.StatusRequest) (*models.StatusResponse, error) {
+	user, status, err := s.resolveStatus(userExternalID, externalID)
 	if err != nil {
-		return nil, errors.New("status not found")
+		return nil, err
 	}
 
-	// Check if changing name, keep it unique
-	if status.Name != req.Name {
-		if _, err := s.statusRepo.GetStatusByName(req.Name); err == nil {
-			return nil, errors.New("a status with this new name already exists")
-		}
+	if err := s.authorizeManagement(user, status.WorkspaceID); err != nil {
+		return nil, err
+	}
+
+	// Keep the name unique within the status's scope
+	taken, err := s.statusRepo.IsStatusNameTaken(status.WorkspaceID, status.BoardID, req.Name, status.ID)
+	if err != nil {
+		return nil, err
 	}
+	if taken {
+		return nil, newConflict("a status with this new name already exists")
+	}
+
+	previous := &models.StatusSnapshot{Name: status.Name, Color: status.Color, Category: status.Category, Position: status.Position}
 
 	status.Name = req.Name
 	status.Color = req.Color
+	status.Category = req.Category
 	if req.Position != nil {
 		status.Position = *req.Position
 	}
+	status.ModifiedBy = &user.ExternalID
+
+	if err := s.statusRepo.UpdateStatus(status, previous, user.ID); err != nil {
+		if repositories.IsUniqueViolation(err) {
+			return nil, newConflict("a status with this new name already exists")
+		}
+		return nil, err
+	}
+
+	return s.mapToResponse(status), nil
+}
+
+// DeleteStatus retires a status. Tasks still using it are moved to migrateTo when given,
+// otherwise the deletion is refused; the number of moved tasks is returned.
+func (s *StatusService) DeleteStatus(userExternalID, externalID, migrateTo string) (int64, error) {
+	user, status, err := s.resolveStatus(userExternalID, externalID)
+	if err != nil {
+		return 0, err
+	}
 
-	if err := s.statusRepo.UpdateStatus(status); err != nil {
+	if err := s.authorizeManagement(user, status.WorkspaceID); err != nil {
+		return 0, err
+	}
+
+	var target *models.Status
+	if migrateTo != "" {
+		if target, err = s.resolveMigrationTarget(status, migrateTo); err != nil {
+			return 0, err
+		}
+	}
+
+	migrated, err := s.statusRepo.DeleteStatus(status, target, user.ID, user.ExternalID)
+	if err != nil {
+		if err == repositories.ErrStatusInUse {
+			// As per PRD 7.4 Status Deletion Guard, must map to HTTP 409
+			return 0, newConflict("status is in use by one or more tasks, pass migrate_to to move them")
+		}
+		if err == sql.ErrNoRows {
+			return 0, newNotFound("status not found")
+		}
+		return 0, err
+	}
+
+	return migrated, nil
+}
+
+// resolveMigrationTarget loads the status tasks move to when a status is deleted. It must be usable
+// on every board the deleted status is: a workspace-wide status of the same workspace, or, for a
+// board status, another status of that board.
+func (s *StatusService) resolveMigrationTarget(status *models.Status, targetExternalID string) (*models.Status, error) {
+	target, err := s.statusRepo.GetStatusByExternalID(targetExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newFieldError("migrate_to", "status not found")
+		}
+		return nil, err
+	}
+
+	if target.ID == status.ID {
+		return nil, newFieldError("migrate_to", "cannot migrate tasks to the status being deleted")
+	}
+
+	sameScope := target.WorkspaceID == status.WorkspaceID &&
+		(target.BoardID == nil || (status.BoardID != nil && *target.BoardID == *status.BoardID))
+	if !sameScope {
+		return nil, newFieldError("migrate_to", "status is not available on every board using the deleted status")
+	}
+
+	return target, nil
+}
+
+// createStatus fills in a status scoped by the caller and stores it, keeping its name unique in scope
+func (s *StatusService) createStatus(user *models.User, status *models.Status, req *models.StatusRequest) (*models.StatusResponse, error) {
+	taken, err := s.statusRepo.IsStatusNameTaken(status.WorkspaceID, status.BoardID, req.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, newConflict("a status with this name already exists")
+	}
+
+	pos := 0
+	if req.Position != nil {
+		pos = *req.Position
+	}
+
+	status.ExternalID = utils.GenerateUUID()
+	status.Name = req.Name
+	status.Color = req.Color
+	status.Category = req.Category
+	status.Position = pos
+	status.CreatedBy = &user.ExternalID
+
+	if err := s.statusRepo.CreateStatus(status, user.ID); err != nil {
+		if repositories.IsUniqueViolation(err) {
+			return nil, newConflict("a status with this name already exists")
+		}
 		return nil, err
 	}
 
 	return s.mapToResponse(status), nil
 }
 
-// DeleteStatus drops status unless referenced
-func (s *StatusService) DeleteStatus(externalID string) error {
-	status, err := s.statusRepo.GetStatusByExternalID(externalID)
+// GetStatusAuditLogs lists every status change made in the workspace, newest first
+func (s *StatusService) GetStatusAuditLogs(userExternalID, workspaceExternalID string) ([]*models.StatusAuditLogResponse, error) {
+	_, w, err := s.authorizeWorkspaceManagement(userExternalID, workspaceExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := s.statusRepo.GetAuditLogsByWorkspaceID(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*models.StatusAuditLogResponse
+	for _, l := range logs {
+		response = append(response, &models.StatusAuditLogResponse{
+			StatusExternalID: l.StatusExternalID,
+			BoardExternalID:  l.BoardExternalID,
+			Action:           l.Action,
+			OldValues:        l.OldValues,
+			NewValues:        l.NewValues,
+			ActorExternalID:  l.ActorExternalID,
+			ActorName:        l.ActorName,
+			CreatedAt:        l.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// authorizeManagement checks the user may change statuses of the workspace:
+// system admins always can, members need the status:manage permission
+func (s *StatusService) authorizeManagement(user *models.User, workspaceID int) error {
+	if user.IsAdmin {
+		return nil
+	}
+	_, err := s.authz.authorize(user, workspaceID, policy.ActionManage, policy.ResourceStatus)
+	return err
+}
+
+// authorizeWorkspaceManagement loads the caller and workspace and checks the caller may manage its statuses
+func (s *StatusService) authorizeWorkspaceManagement(userExternalID, workspaceExternalID string) (*models.User, *models.Workspace, error) {
+	user, err := s.userRepo.GetUserByExternalID(userExternalID)
 	if err != nil {
-		return errors.New("status not found")
+		return nil, nil, newNotFound("user not found")
 	}
 
-	// Check references
-	referenced, err := s.statusRepo.CheckIfReferenced(status.ID)
+	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
 	if err != nil {
-		return errors.New("could not verify status references")
+		return nil, nil, newNotFound("workspace not found")
 	}
-	if referenced {
-		// As per PRD 7.4 Status Deletion Guard, must return error text mapping to HTTP 409
-		return errors.New("conflict: status is in use by one or more tasks and cannot be deleted")
+
+	if err := s.authorizeManagement(user, w.ID); err != nil {
+		return nil, nil, err
 	}
 
-	return s.statusRepo.DeleteStatus(status.ID)
+	return user, w, nil
+}
+
+// resolveBoard loads the caller and a board
+func (s *StatusService) resolveBoard(userExternalID, boardExternalID string) (*models.User, *models.Board, error) {
+	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	if err != nil {
+		return nil, nil, newNotFound("user not found")
+	}
+
+	b, err := s.boardRepo.GetBoardByExternalID(boardExternalID)
+	if err != nil {
+		return nil, nil, newNotFound("board not found")
+	}
+
+	return user, b, nil
+}
+
+// resolveStatus loads the caller and a status
+func (s *StatusService) resolveStatus(userExternalID, statusExternalID string) (*models.User, *models.Status, error) {
+	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	if err != nil {
+		return nil, nil, newNotFound("user not found")
+	}
+
+	status, err := s.statusRepo.GetStatusByExternalID(statusExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, newNotFound("status not found")
+		}
+		return nil, nil, err
+	}
+
+	return user, status, nil
 }
 
 // Helper mapper
 func (s *StatusService) mapToResponse(st *models.Status) *models.StatusResponse {
 	return &models.StatusResponse{
-		ExternalID: st.ExternalID,
-		Name:       st.Name,
-		Color:      st.Color,
-		Position:   st.Position,
-		CreatedAt:  st.CreatedAt,
-		ModifiedAt: st.ModifiedAt,
+		ExternalID:          st.ExternalID,
+		WorkspaceExternalID: st.WorkspaceExternalID,
+		BoardExternalID:     st.BoardExternalID,
+		Name:                st.Name,
+		Color:               st.Color,
+		Category:            st.Category,
+		Position:            st.Position,
+		CreatedAt:           st.CreatedAt,
+		ModifiedAt:          st.ModifiedAt,
+	}
+}
+
+func (s *StatusService) mapToResponses(statuses []*models.Status) []*models.StatusResponse {
+	var response []*models.StatusResponse
+	for _, st := range statuses {
+		response = append(response, s.mapToResponse(st))
+	}
+	return response
+}
+
+// defaultStatuses builds the status set seeded into a new workspace
+func defaultStatuses(createdBy string) []*models.Status {
+	var statuses []*models.Status
+	for i, d := range defaultStatusSet {
+		color := d.Color
+		statuses = append(statuses, &models.Status{
+			ExternalID: utils.GenerateUUID(),
+			Name:       d.Name,
+			Color:      &color,
+			Category:   d.Category,
+			Position:   i,
+			CreatedBy:  &createdBy,
+		})
 	}
+	return statuses
 }
@@ -1,28 +1,44 @@
 package services
 
 import (
-	"errors"
+	"database/sql"
+	"fmt"
+	"strings"
 
 	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
 	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/storage"
 	"github.com/grahagandangr/nexboard-be/utils"
 )
 
 type TaskService struct {
-	taskRepo      *repositories.TaskRepository
-	boardRepo     *repositories.BoardRepository
-	statusRepo    *repositories.StatusRepository
-	userRepo      *repositories.UserRepository
-	workspaceRepo *repositories.WorkspaceRepository
+	taskRepo       *repositories.TaskRepository
+	boardRepo      *repositories.BoardRepository
+	statusRepo     *repositories.StatusRepository
+	userRepo       *repositories.UserRepository
+	workspaceRepo  *repositories.WorkspaceRepository
+	dependencyRepo *repositories.TaskDependencyRepository
+	labelRepo      *repositories.LabelRepository
+	blobs          storage.BlobStore
+	subtaskPolicy  string // What deleting a task does to its children, see models.SubtaskDelete*
+	blockedPolicy  string // Completing a task with open blockers, see models.BlockedCompletion*
+	authz          *workspaceAuthorizer
 }
 
-func NewTaskService(taskRepo *repositories.TaskRepository, boardRepo *repositories.BoardRepository, statusRepo *repositories.StatusRepository, userRepo *repositories.UserRepository, workspaceRepo *repositories.WorkspaceRepository) *TaskService {
+func NewTaskService(taskRepo *repositories.TaskRepository, boardRepo *repositories.BoardRepository, statusRepo *repositories.StatusRepository, userRepo *repositories.UserRepository, workspaceRepo *repositories.WorkspaceRepository, dependencyRepo *repositories.TaskDependencyRepository, labelRepo *repositories.LabelRepository, blobs storage.BlobStore, subtaskPolicy, blockedPolicy string) *TaskService {
 	return &TaskService{
-		taskRepo:      taskRepo,
-		boardRepo:     boardRepo,
-		statusRepo:    statusRepo,
-		userRepo:      userRepo,
-		workspaceRepo: workspaceRepo,
+		taskRepo:       taskRepo,
+		boardRepo:      boardRepo,
+		statusRepo:     statusRepo,
+		userRepo:       userRepo,
+		workspaceRepo:  workspaceRepo,
+		dependencyRepo: dependencyRepo,
+		labelRepo:      labelRepo,
+		blobs:          blobs,
+		subtaskPolicy:  subtaskPolicy,
+		blockedPolicy:  blockedPolicy,
+		authz:          newTaskAuthorizer(userRepo, workspaceRepo, boardRepo, taskRepo),
 	}
 }
 
@@ -30,41 +46,32 @@ func NewTaskService(taskRepo *repositories.TaskRepository, boardRepo *repositori
 func (s *TaskService) CreateTask(userExternalID, boardExternalID string, req *models.TaskRequest) (*models.Task, error) {
 	user, err := s.userRepo.GetUserByExternalID(userExternalID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, newNotFound("user not found")
 	}
 
 	board, err := s.boardRepo.GetBoardByExternalID(boardExternalID)
 	if err != nil {
-		return nil, errors.New("board not found")
+		return nil, newNotFound("board not found")
 	}
 
-	// Make sure user belongs to the workspace
-	_, err = s.workspaceRepo.GetMemberRole(board.WorkspaceID, user.ID)
-	if err != nil {
-		return nil, errors.New("unauthorized: not a member of the workspace")
+	if _, err := s.authz.authorize(user, board.WorkspaceID, policy.ActionCreate, policy.ResourceTask); err != nil {
+		return nil, err
 	}
 
-	// Verify status exists
-	status, err := s.statusRepo.GetStatusByExternalID(req.StatusExternalID)
+	status, err := s.resolveStatus(board, req.StatusExternalID)
 	if err != nil {
-		return nil, errors.New("invalid status_external_id")
+		return nil, err
 	}
 
 	// Assignee resolution
-	var assignedTo *int
-	if req.AssignedToExternalID != nil {
-		assignee, err := s.userRepo.GetUserByExternalID(*req.AssignedToExternalID)
-		if err != nil {
-			return nil, errors.New("invalid assigned_to_external_id")
-		}
-		
-		// Optional: Verify assignee is a member of workspace
-		_, err = s.workspaceRepo.GetMemberRole(board.WorkspaceID, assignee.ID)
-		if err != nil {
-			return nil, errors.New("cannot assign task to a non-member")
-		}
+	assignedTo, err := s.resolveAssignee(board.WorkspaceID, req.AssignedToExternalID)
+	if err != nil {
+		return nil, err
+	}
 
-		assignedTo = &assignee.ID
+	parentID, err := s.resolveParent(board, req.ParentTaskExternalID)
+	if err != nil {
+		return nil, err
 	}
 
 	priority := req.Priority
@@ -73,16 +80,16 @@ func (s *TaskService) CreateTask(userExternalID, boardExternalID string, req *mo
 	}
 
 	task := &models.Task{
-		ExternalID:  utils.GenerateUUID(),
-		BoardID:     board.ID,
-		StatusID:    status.ID,
-		AssignedTo:  assignedTo,
-		CreatedByID: user.ID,
-		Title:       req.Title,
-		Description: req.Description,
-		Priority:    priority,
-		DueDate:     req.DueDate,
-		Position:    0, // Will be set to bottom of list in reality, but default to 0 for simplified setup
+		ExternalID:   utils.GenerateUUID(),
+		BoardID:      board.ID,
+		StatusID:     status.ID,
+		ParentTaskID: parentID,
+		AssignedTo:   assignedTo,
+		CreatedByID:  user.ID,
+		Title:        req.Title,
+		Description:  req.Description,
+		Priority:     priority,
+		DueDate:      req.DueDate,
 	}
 
 	if err := s.taskRepo.CreateTask(task); err != nil {
@@ -92,52 +99,96 @@ func (s *TaskService) CreateTask(userExternalID, boardExternalID string, req *mo
 	return task, nil
 }
 
-// GetBoardTasks fetches all tasks for a specific board
-func (s *TaskService) GetBoardTasks(userExternalID, boardExternalID string) ([]*models.TaskResponse, error) {
+// GetBoardTasks fetches the tasks of a board; state optionally narrows them to open or completed ones,
+// and labels to tasks carrying any (the default) or all of the given workspace labels
+func (s *TaskService) GetBoardTasks(userExternalID, boardExternalID, state string, labelParams []string, labelsMatch string) ([]*models.TaskResponse, error) {
+	if state != "" && state != models.TaskStateOpen && state != models.TaskStateCompleted {
+		return nil, newFieldError("state", "must be one of: open completed")
+	}
+	if labelsMatch != "" && labelsMatch != models.LabelMatchAny && labelsMatch != models.LabelMatchAll {
+		return nil, newFieldError("labels_match", "must be one of: any all")
+	}
+
 	user, err := s.userRepo.GetUserByExternalID(userExternalID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, newNotFound("user not found")
 	}
 
 	board, err := s.boardRepo.GetBoardByExternalID(boardExternalID)
 	if err != nil {
-		return nil, errors.New("board not found")
+		return nil, newNotFound("board not found")
+	}
+
+	if _, err := s.authz.authorize(user, board.WorkspaceID, policy.ActionView, policy.ResourceTask); err != nil {
+		return nil, err
+	}
+
+	labels, err := s.resolveLabelFilter(board, labelParams, labelsMatch == models.LabelMatchAll)
+	if err != nil {
+		return nil, err
 	}
 
-	// Verify accessibility
-	_, err = s.workspaceRepo.GetMemberRole(board.WorkspaceID, user.ID)
+	return s.taskRepo.GetTasksByBoardID(board.ID, state, labels)
+}
+
+// resolveLabelFilter turns ?labels= values, repeated or comma separated, into label IDs of the board's workspace
+func (s *TaskService) resolveLabelFilter(board *models.Board, labelParams []string, matchAll bool) (*models.TaskLabelFilter, error) {
+	var externalIDs []string
+	seen := make(map[string]bool)
+	for _, param := range labelParams {
+		for _, extID := range strings.Split(param, ",") {
+			extID = strings.TrimSpace(extID)
+			if extID == "" || seen[extID] {
+				continue
+			}
+			seen[extID] = true
+			externalIDs = append(externalIDs, extID)
+		}
+	}
+	if len(externalIDs) == 0 {
+		return nil, nil
+	}
+
+	labels, err := s.labelRepo.GetLabelsByExternalIDs(board.WorkspaceID, externalIDs)
 	if err != nil {
-		return nil, errors.New("unauthorized: not a member of the workspace")
+		return nil, err
+	}
+	if len(labels) != len(externalIDs) {
+		return nil, newFieldError("labels", "must only contain labels of this workspace")
 	}
 
-	return s.taskRepo.GetTasksByBoardID(board.ID)
+	filter := &models.TaskLabelFilter{MatchAll: matchAll}
+	for _, l := range labels {
+		filter.LabelIDs = append(filter.LabelIDs, int64(l.ID))
+	}
+	return filter, nil
 }
 
 // UpdateTask completely overrides task details
 func (s *TaskService) UpdateTask(userExternalID, taskExternalID string, req *models.TaskRequest) (*models.TaskResponse, error) {
-	_, err := s.userRepo.GetUserByExternalID(userExternalID)
+	user, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 
-	task, err := s.taskRepo.GetTaskByExternalID(taskExternalID)
+	status, err := s.resolveStatus(board, req.StatusExternalID)
 	if err != nil {
-		return nil, errors.New("task not found")
+		return nil, err
 	}
 
-	// Make sure the new status exists
-	status, err := s.statusRepo.GetStatusByExternalID(req.StatusExternalID)
+	assignedTo, err := s.resolveAssignee(board.WorkspaceID, req.AssignedToExternalID)
 	if err != nil {
-		return nil, errors.New("invalid status_external_id")
+		return nil, err
 	}
 
-	var assignedTo *int
-	if req.AssignedToExternalID != nil {
-		assignee, err := s.userRepo.GetUserByExternalID(*req.AssignedToExternalID)
-		if err != nil {
-			return nil, errors.New("invalid assigned_to_external_id")
-		}
-		assignedTo = &assignee.ID
+	parentID, err := s.resolveParent(board, req.ParentTaskExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := s.checkBlockers(task, status)
+	if err != nil {
+		return nil, err
 	}
 
 	priority := req.Priority
@@ -151,92 +202,263 @@ func (s *TaskService) UpdateTask(userExternalID, taskExternalID string, req *mod
 	task.DueDate = req.DueDate
 	task.StatusID = status.ID
 	task.AssignedTo = assignedTo
+	task.ParentTaskID = parentID
+	task.ModifiedBy = &user.ExternalID
 
 	if err := s.taskRepo.UpdateTask(task); err != nil {
+		if err == repositories.ErrTaskCycle {
+			return nil, newFieldError("parent_task_external_id", "cannot be the task itself or one of its subtasks")
+		}
+		return nil, err
+	}
+
+	return s.getTaskResponseWithWarnings(taskExternalID, warnings)
+}
+
+// GetChildTasks lists the direct subtasks of a task, wherever they live in the workspace
+func (s *TaskService) GetChildTasks(userExternalID, taskExternalID string) ([]*models.TaskResponse, error) {
+	_, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
 		return nil, err
 	}
 
-	// Just return success message, returning full response takes another DB query. Better to let user hit GET again as standard, or return mock.
-	// But let's build standard response:
-	return s.GetTask(userExternalID, taskExternalID)
+	children, err := s.taskRepo.GetChildTasks(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if children == nil {
+		children = []*models.TaskResponse{}
+	}
+	return children, nil
+}
+
+// GetEpic returns a task with its subtasks rolled up by status category
+func (s *TaskService) GetEpic(userExternalID, taskExternalID string) (*models.EpicResponse, error) {
+	_, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	tr, err := s.getTaskResponse(taskExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	childCount, rollup, err := s.taskRepo.GetSubtaskRollup(task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EpicResponse{
+		Task:       tr,
+		ChildCount: childCount,
+		Subtasks:   *rollup,
+	}, nil
 }
 
 // MoveTaskStatus only updates the status of a task
 func (s *TaskService) MoveTaskStatus(userExternalID, taskExternalID string, req *models.MoveTaskStatusRequest) (*models.TaskResponse, error) {
-	_, err := s.userRepo.GetUserByExternalID(userExternalID)
+	user, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 
-	task, err := s.taskRepo.GetTaskByExternalID(taskExternalID)
+	status, err := s.resolveStatus(board, req.StatusExternalID)
 	if err != nil {
-		return nil, errors.New("task not found")
+		return nil, err
 	}
 
-	// Make sure new status exists
-	status, err := s.statusRepo.GetStatusByExternalID(req.StatusExternalID)
+	warnings, err := s.checkBlockers(task, status)
 	if err != nil {
-		return nil, errors.New("invalid status_external_id")
+		return nil, err
 	}
 
 	task.StatusID = status.ID
+	task.ModifiedBy = &user.ExternalID
 
 	if err := s.taskRepo.UpdateTask(task); err != nil {
 		return nil, err
 	}
 
-	return s.GetTask(userExternalID, taskExternalID)
+	return s.getTaskResponseWithWarnings(taskExternalID, warnings)
 }
 
-// AssignTask assigns or unassigns a member to the task
-func (s *TaskService) AssignTask(userExternalID, taskExternalID string, req *models.AssignTaskRequest) (*models.TaskResponse, error) {
-	_, err := s.userRepo.GetUserByExternalID(userExternalID)
+// MoveTask drops a task into a status column between two neighbors, for drag-and-drop boards
+func (s *TaskService) MoveTask(userExternalID, taskExternalID string, req *models.MoveTaskRequest) (*models.TaskResponse, error) {
+	user, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.resolveStatus(board, req.StatusExternalID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 
-	task, err := s.taskRepo.GetTaskByExternalID(taskExternalID)
+	warnings, err := s.checkBlockers(task, status)
 	if err != nil {
-		return nil, errors.New("task not found")
+		return nil, err
 	}
 
-	var assignedTo *int
-	if req.AssignedToExternalID != nil {
-		assignee, err := s.userRepo.GetUserByExternalID(*req.AssignedToExternalID)
-		if err != nil {
-			return nil, errors.New("invalid assigned_to_external_id")
+	if err := s.taskRepo.MoveTask(task, status.ID, req.AfterTaskExternalID, req.BeforeTaskExternalID, user.ExternalID); err != nil {
+		if err == repositories.ErrInvalidNeighbor {
+			field := "before_task_external_id"
+			if req.AfterTaskExternalID != nil {
+				field = "after_task_external_id"
+			}
+			return nil, newFieldError(field, "must be another task in the target status on this board, with after above before")
 		}
-		assignedTo = &assignee.ID
+		return nil, err
+	}
+
+	return s.getTaskResponseWithWarnings(taskExternalID, warnings)
+}
+
+// AssignTask assigns or unassigns a member to the task
+func (s *TaskService) AssignTask(userExternalID, taskExternalID string, req *models.AssignTaskRequest) (*models.TaskResponse, error) {
+	user, task, board, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	assignedTo, err := s.resolveAssignee(board.WorkspaceID, req.AssignedToExternalID)
+	if err != nil {
+		return nil, err
 	}
 
 	task.AssignedTo = assignedTo
-	
+	task.ModifiedBy = &user.ExternalID
+
 	if err := s.taskRepo.UpdateTask(task); err != nil {
 		return nil, err
 	}
 
-	return s.GetTask(userExternalID, taskExternalID)
+	return s.getTaskResponse(taskExternalID)
 }
 
-// DeleteTask drops task
+// DeleteTask drops task along with its attachment files; its subtasks follow the configured policy
 func (s *TaskService) DeleteTask(userExternalID, taskExternalID string) error {
-	_, err := s.userRepo.GetUserByExternalID(userExternalID)
+	_, task, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionDelete)
 	if err != nil {
-		return errors.New("user not found")
+		return err
 	}
 
-	task, err := s.taskRepo.GetTaskByExternalID(taskExternalID)
+	keys, err := s.taskRepo.DeleteTask(task.ID, s.subtaskPolicy)
 	if err != nil {
-		return errors.New("task not found")
+		if err == repositories.ErrTaskHasSubtasks {
+			return newConflict("task has subtasks, delete or detach them first")
+		}
+		return err
 	}
-
-	return s.taskRepo.DeleteTask(task.ID)
+	removeBlobs(s.blobs, keys)
+	return nil
 }
 
-// GetTask helper to fetch fully populated task view bypassing Board loop
+// GetTask fetches a fully populated task view for a workspace member
 func (s *TaskService) GetTask(userExternalID, taskExternalID string) (*models.TaskResponse, error) {
-	// Let's implement this inside repository safely by using an array and grabbing the first. Since GetTasksByBoard is already built, it requires boardID.
-	// Alternatively, built a direct fetch. Currently, it's easier to fetch everything and search.
-	// Let's just create a custom repo method quickly if needed, but for now we'll do an inline fake return since API asks for simple returns or standard GET.
-	return &models.TaskResponse{ExternalID: taskExternalID}, nil // Simple stub to prevent errors, actual implementation can expand repo.
+	if _, _, _, _, err := s.authz.authorizeTask(userExternalID, taskExternalID, policy.ActionView); err != nil {
+		return nil, err
+	}
+
+	return s.getTaskResponse(taskExternalID)
+}
+
+// resolveStatus loads a status that tasks on the board may use: one of its workspace's or the board's own
+func (s *TaskService) resolveStatus(board *models.Board, statusExternalID string) (*models.Status, error) {
+	status, err := s.statusRepo.GetStatusForBoard(statusExternalID, board.ID, board.WorkspaceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newFieldError("status_external_id", "status not found on this board")
+		}
+		return nil, err
+	}
+	return status, nil
+}
+
+// resolveAssignee maps an optional assignee external ID to a user ID, requiring workspace membership
+func (s *TaskService) resolveAssignee(workspaceID int, assigneeExternalID *string) (*int, error) {
+	return resolveWorkspaceAssignee(s.userRepo, s.workspaceRepo, workspaceID, assigneeExternalID)
+}
+
+// resolveParent maps an optional parent task external ID to a task ID; the parent must belong to the same workspace
+func (s *TaskService) resolveParent(board *models.Board, parentExternalID *string) (*int, error) {
+	if parentExternalID == nil {
+		return nil, nil
+	}
+
+	parent, err := s.taskRepo.GetTaskByExternalID(*parentExternalID)
+	if err != nil {
+		return nil, newFieldError("parent_task_external_id", "task not found in this workspace")
+	}
+
+	parentBoard, err := s.boardRepo.GetBoardByID(parent.BoardID)
+	if err != nil || parentBoard.WorkspaceID != board.WorkspaceID {
+		return nil, newFieldError("parent_task_external_id", "task not found in this workspace")
+	}
+
+	return &parent.ID, nil
+}
+
+// resolveWorkspaceAssignee is shared by everything that can be assigned to a workspace member
+func resolveWorkspaceAssignee(userRepo *repositories.UserRepository, workspaceRepo *repositories.WorkspaceRepository, workspaceID int, assigneeExternalID *string) (*int, error) {
+	if assigneeExternalID == nil {
+		return nil, nil
+	}
+
+	assignee, err := userRepo.GetUserByExternalID(*assigneeExternalID)
+	if err != nil {
+		return nil, newFieldError("assigned_to_external_id", "user not found")
+	}
+
+	_, err = workspaceRepo.GetMemberRole(workspaceID, assignee.ID)
+	if err != nil {
+		return nil, newFieldError("assigned_to_external_id", "user is not a member of the workspace")
+	}
+
+	return &assignee.ID, nil
+}
+
+// checkBlockers applies the blocked completion policy to a task about to enter a status. Only tasks
+// that are not completed yet and move into a done status are checked. With the warn policy the
+// returned warnings go back to the caller; with refuse the move fails.
+func (s *TaskService) checkBlockers(task *models.Task, status *models.Status) ([]string, error) {
+	if status.Category != models.StatusCategoryDone || task.CompletedAt != nil {
+		return nil, nil
+	}
+
+	open, err := s.dependencyRepo.CountOpenBlockers(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if open == 0 {
+		return nil, nil
+	}
+
+	message := fmt.Sprintf("task is blocked by %d open task(s)", open)
+	if s.blockedPolicy == models.BlockedCompletionRefuse {
+		return nil, newConflict(message)
+	}
+	return []string{message}, nil
+}
+
+// getTaskResponseWithWarnings loads the joined task view and attaches warnings about the change just made
+func (s *TaskService) getTaskResponseWithWarnings(taskExternalID string, warnings []string) (*models.TaskResponse, error) {
+	tr, err := s.getTaskResponse(taskExternalID)
+	if err != nil {
+		return nil, err
+	}
+	tr.Warnings = warnings
+	return tr, nil
+}
+
+// getTaskResponse loads the joined task view without any permission checks
+func (s *TaskService) getTaskResponse(taskExternalID string) (*models.TaskResponse, error) {
+	tr, err := s.taskRepo.GetTaskResponseByExternalID(taskExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newNotFound("task not found")
+		}
+		return nil, err
+	}
+	return tr, nil
 }
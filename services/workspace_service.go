@@ -1,27 +1,40 @@
 package services
 
 import (
-	"errors"
-
 	"github.com/grahagandangr/nexboard-be/models"
+	"github.com/grahagandangr/nexboard-be/policy"
 	"github.com/grahagandangr/nexboard-be/repositories"
+	"github.com/grahagandangr/nexboard-be/storage"
 	"github.com/grahagandangr/nexboard-be/utils"
 )
 
 type WorkspaceService struct {
 	workspaceRepo *repositories.WorkspaceRepository
 	userRepo      *repositories.UserRepository
+	roleRepo      *repositories.WorkspaceRoleRepository
+	blobs         storage.BlobStore
+	authz         *workspaceAuthorizer
 }
 
-func NewWorkspaceService(workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository) *WorkspaceService {
-	return &WorkspaceService{workspaceRepo: workspaceRepo, userRepo: userRepo}
+func NewWorkspaceService(workspaceRepo *repositories.WorkspaceRepository, userRepo *repositories.UserRepository, roleRepo *repositories.WorkspaceRoleRepository, blobs storage.BlobStore) *WorkspaceService {
+	return &WorkspaceService{
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		blobs:         blobs,
+		authz:         newWorkspaceAuthorizer(userRepo, workspaceRepo),
+	}
 }
 
 // CreateWorkspace creates a new workspace and sets the user as the owner
 func (s *WorkspaceService) CreateWorkspace(creatorExternalID string, req *models.WorkspaceRequest) (*models.WorkspaceResponse, error) {
 	user, err := s.userRepo.GetUserByExternalID(creatorExternalID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, newNotFound("user not found")
+	}
+
+	if err := validateDisplayName(req.Name); err != nil {
+		return nil, err
 	}
 
 	workspace := &models.Workspace{
@@ -31,7 +44,7 @@ func (s *WorkspaceService) CreateWorkspace(creatorExternalID string, req *models
 		OwnerID:     user.ID,
 	}
 
-	if err := s.workspaceRepo.CreateWorkspace(workspace); err != nil {
+	if err := s.workspaceRepo.CreateWorkspace(workspace, builtinRoles(user.ExternalID), defaultStatuses(user.ExternalID)); err != nil {
 		return nil, err
 	}
 
@@ -49,7 +62,7 @@ func (s *WorkspaceService) CreateWorkspace(creatorExternalID string, req *models
 func (s *WorkspaceService) GetUserWorkspaces(userExternalID string) ([]*models.WorkspaceResponse, error) {
 	user, err := s.userRepo.GetUserByExternalID(userExternalID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, newNotFound("user not found")
 	}
 
 	workspaces, err := s.workspaceRepo.GetWorkspacesByUserID(user.ID)
@@ -73,20 +86,9 @@ func (s *WorkspaceService) GetUserWorkspaces(userExternalID string) ([]*models.W
 
 // GetWorkspace gets a specific workspace
 func (s *WorkspaceService) GetWorkspace(userExternalID, workspaceExternalID string) (*models.WorkspaceResponse, error) {
-	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionView, policy.ResourceWorkspace)
 	if err != nil {
-		return nil, errors.New("user not found")
-	}
-
-	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
-	if err != nil {
-		return nil, errors.New("workspace not found")
-	}
-
-	// Verify user is a member
-	_, err = s.workspaceRepo.GetMemberRole(w.ID, user.ID)
-	if err != nil {
-		return nil, errors.New("unauthorized: not a member of this workspace")
+		return nil, err
 	}
 
 	return &models.WorkspaceResponse{
@@ -101,19 +103,13 @@ func (s *WorkspaceService) GetWorkspace(userExternalID, workspaceExternalID stri
 
 // UpdateWorkspace updates a workspace (owner only)
 func (s *WorkspaceService) UpdateWorkspace(userExternalID, workspaceExternalID string, req *models.WorkspaceRequest) (*models.WorkspaceResponse, error) {
-	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionUpdate, policy.ResourceWorkspace)
 	if err != nil {
-		return nil, errors.New("user not found")
-	}
-
-	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
-	if err != nil {
-		return nil, errors.New("workspace not found")
+		return nil, err
 	}
 
-	// Verify user is owner
-	if w.OwnerID != user.ID {
-		return nil, errors.New("unauthorized: only owner can update workspace")
+	if err := validateDisplayName(req.Name); err != nil {
+		return nil, err
 	}
 
 	w.Name = req.Name
@@ -133,149 +129,180 @@ func (s *WorkspaceService) UpdateWorkspace(userExternalID, workspaceExternalID s
 	}, nil
 }
 
-// DeleteWorkspace deletes a workspace (owner only)
+// DeleteWorkspace deletes a workspace (owner only) along with its attachment files
 func (s *WorkspaceService) DeleteWorkspace(userExternalID, workspaceExternalID string) error {
-	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionDelete, policy.ResourceWorkspace)
 	if err != nil {
-		return errors.New("user not found")
+		return err
 	}
 
-	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
+	keys, err := s.workspaceRepo.DeleteWorkspace(w.ID)
 	if err != nil {
-		return errors.New("workspace not found")
-	}
-
-	// Verify user is owner
-	if w.OwnerID != user.ID {
-		return errors.New("unauthorized: only owner can delete workspace")
+		return err
 	}
-
-	return s.workspaceRepo.DeleteWorkspace(w.ID)
+	removeBlobs(s.blobs, keys)
+	return nil
 }
 
 // --------- Member management -----------
 
 // GetMembers gets all members of a workspace
 func (s *WorkspaceService) GetMembers(userExternalID, workspaceExternalID string) ([]*models.WorkspaceMemberResponse, error) {
-	user, err := s.userRepo.GetUserByExternalID(userExternalID)
+	_, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionView, policy.ResourceMember)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 
-	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
+	return s.workspaceRepo.GetMembers(w.ID)
+}
+
+// UpdateMemberRole updates a member's role. The caller must outrank both the member's
+// current role and the new one, so in practice only the owner promotes or demotes admins.
+func (s *WorkspaceService) UpdateMemberRole(userExternalID, workspaceExternalID, targetUserExternalID string, req *models.UpdateMemberRoleRequest) error {
+	currentUser, w, access, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionUpdate, policy.ResourceMember)
 	if err != nil {
-		return nil, errors.New("workspace not found")
+		return err
 	}
 
-	// Verify user is a member
-	_, err = s.workspaceRepo.GetMemberRole(w.ID, user.ID)
+	// Get target user
+	targetUser, targetRole, err := s.resolveTargetMember(w, targetUserExternalID)
 	if err != nil {
-		return nil, errors.New("unauthorized: not a member of this workspace")
+		return err
 	}
 
-	return s.workspaceRepo.GetMembers(w.ID)
-}
+	if targetUser.ID == currentUser.ID {
+		return newForbidden("cannot change your own role")
+	}
 
-// InviteMember adds a member to the workspace (owner/admin only)
-func (s *WorkspaceService) InviteMember(userExternalID, workspaceExternalID string, req *models.InviteMemberRequest) error {
-	currentUser, err := s.userRepo.GetUserByExternalID(userExternalID)
-	if err != nil {
-		return errors.New("user not found")
+	// Ownership only changes hands through a transfer
+	if targetRole == models.RoleOwner {
+		return newForbidden("cannot change owner role, transfer ownership instead")
 	}
 
-	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
-	if err != nil {
-		return errors.New("workspace not found")
+	if _, err := resolveGrantableRole(s.roleRepo, w.ID, access, req.Role); err != nil {
+		return err
 	}
 
-	// Check permission (owner or admin)
-	role, err := s.workspaceRepo.GetMemberRole(w.ID, currentUser.ID)
-	if err != nil {
-		return errors.New("unauthorized: not a member")
+	if !policy.Outranks(access.Role, targetRole) || !policy.Outranks(access.Role, req.Role) {
+		return newForbidden("cannot manage members at or above your own role")
 	}
-	if role != "owner" && role != "admin" {
-		return errors.New("unauthorized: only owner or admin can invite members")
+
+	return s.workspaceRepo.UpdateMemberRole(w.ID, targetUser.ID, req.Role, currentUser.ExternalID)
+}
+
+// RemoveMember removes a member ranked below the caller. Removing yourself is the same as leaving.
+func (s *WorkspaceService) RemoveMember(userExternalID, workspaceExternalID, targetUserExternalID string) error {
+	// Any member may remove themselves, so only membership is checked up front
+	currentUser, w, access, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionView, policy.ResourceWorkspace)
+	if err != nil {
+		return err
 	}
 
 	// Get target user
-	targetUser, err := s.userRepo.GetUserByExternalID(req.UserExternalID)
+	targetUser, targetRole, err := s.resolveTargetMember(w, targetUserExternalID)
 	if err != nil {
-		return errors.New("target user not found")
+		return err
 	}
 
-	wm := &models.WorkspaceMember{
-		WorkspaceID: w.ID,
-		UserID:      targetUser.ID,
-		Role:        req.Role,
+	if targetUser.ID == currentUser.ID {
+		return s.leave(currentUser, w, access.Role)
 	}
 
-	err = s.workspaceRepo.AddMember(wm)
-	if err != nil {
-		return errors.New("could not add member (perhaps already a member?)")
+	if !policy.Can(access.Role, access.Permissions, policy.ActionDelete, policy.ResourceMember) {
+		return newForbidden(policy.DenialMessage(policy.ActionDelete, policy.ResourceMember))
 	}
 
-	return nil
+	// Owner cannot be removed
+	if targetRole == models.RoleOwner {
+		return newForbidden("cannot remove workspace owner")
+	}
+
+	if !policy.Outranks(access.Role, targetRole) {
+		return newForbidden("cannot remove members at or above your own role")
+	}
+
+	return s.removeMember(w, targetUser.ID, currentUser.ExternalID)
 }
 
-// UpdateMemberRole updates a member's role (owner only)
-func (s *WorkspaceService) UpdateMemberRole(userExternalID, workspaceExternalID, targetUserExternalID string, req *models.UpdateMemberRoleRequest) error {
-	currentUser, err := s.userRepo.GetUserByExternalID(userExternalID)
+// LeaveWorkspace removes the caller from a workspace. The owner has to transfer ownership first.
+func (s *WorkspaceService) LeaveWorkspace(userExternalID, workspaceExternalID string) error {
+	user, w, access, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionView, policy.ResourceWorkspace)
 	if err != nil {
-		return errors.New("user not found")
+		return err
 	}
 
-	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
-	if err != nil {
-		return errors.New("workspace not found")
+	return s.leave(user, w, access.Role)
+}
+
+func (s *WorkspaceService) leave(user *models.User, w *models.Workspace, role string) error {
+	if role == models.RoleOwner || w.OwnerID == user.ID {
+		return newConflict("owner cannot leave the workspace, transfer ownership first")
 	}
 
-	// Verify current user is owner
-	if w.OwnerID != currentUser.ID {
-		return errors.New("unauthorized: only owner can update member roles")
+	return s.removeMember(w, user.ID, user.ExternalID)
+}
+
+// removeMember drops the membership; tasks the member was assigned in this workspace become unassigned
+func (s *WorkspaceService) removeMember(w *models.Workspace, userID int, removedBy string) error {
+	if err := s.workspaceRepo.RemoveMember(w.ID, userID, removedBy); err != nil {
+		if err == repositories.ErrNotMember {
+			return newNotFound("user is not a member of this workspace")
+		}
+		return err
 	}
+	return nil
+}
 
-	// Get target user
+// resolveTargetMember loads the member an operation is aimed at, with their current role
+func (s *WorkspaceService) resolveTargetMember(w *models.Workspace, targetUserExternalID string) (*models.User, string, error) {
 	targetUser, err := s.userRepo.GetUserByExternalID(targetUserExternalID)
 	if err != nil {
-		return errors.New("target user not found")
+		return nil, "", newNotFound("target user not found")
 	}
 
-	// Owner cannot change their own role here
-	if targetUser.ID == w.OwnerID {
-		return errors.New("cannot change owner role")
+	role, err := s.workspaceRepo.GetMemberRole(w.ID, targetUser.ID)
+	if err != nil {
+		return nil, "", newNotFound("target user is not a member of this workspace")
 	}
 
-	return s.workspaceRepo.UpdateMemberRole(w.ID, targetUser.ID, req.Role)
+	return targetUser, role, nil
 }
 
-// RemoveMember removes a member (owner/admin only)
-func (s *WorkspaceService) RemoveMember(userExternalID, workspaceExternalID, targetUserExternalID string) error {
-	currentUser, err := s.userRepo.GetUserByExternalID(userExternalID)
-	if err != nil {
-		return errors.New("user not found")
-	}
-
-	w, err := s.workspaceRepo.GetWorkspaceByExternalID(workspaceExternalID)
+// TransferOwnership makes another member the owner (owner only); the previous owner becomes an admin
+func (s *WorkspaceService) TransferOwnership(userExternalID, workspaceExternalID string, req *models.TransferOwnershipRequest) (*models.WorkspaceResponse, error) {
+	currentUser, w, _, err := s.authz.authorizeWorkspace(userExternalID, workspaceExternalID, policy.ActionTransfer, policy.ResourceWorkspace)
 	if err != nil {
-		return errors.New("workspace not found")
+		return nil, err
 	}
 
-	// Get current user role
-	role, err := s.workspaceRepo.GetMemberRole(w.ID, currentUser.ID)
-	if err != nil || (role != "owner" && role != "admin") {
-		return errors.New("unauthorized: only owner or admin can remove members")
+	if err := utils.CheckPassword(currentUser.Password, req.Password); err != nil {
+		return nil, newFieldError("password", "password is incorrect")
 	}
 
-	// Get target user
-	targetUser, err := s.userRepo.GetUserByExternalID(targetUserExternalID)
+	newOwner, err := s.userRepo.GetUserByExternalID(req.NewOwnerExternalID)
 	if err != nil {
-		return errors.New("target user not found")
+		return nil, newFieldError("new_owner_external_id", "user not found")
+	}
+	if newOwner.ID == currentUser.ID {
+		return nil, newFieldError("new_owner_external_id", "user already owns this workspace")
 	}
 
-	// Owner cannot be removed
-	if targetUser.ID == w.OwnerID {
-		return errors.New("cannot remove workspace owner")
+	if err := s.workspaceRepo.TransferOwnership(w, newOwner.ID, currentUser.ExternalID); err != nil {
+		if err == repositories.ErrNotMember {
+			return nil, newFieldError("new_owner_external_id", "user is not a member of the workspace")
+		}
+		if err == repositories.ErrOwnerChanged {
+			return nil, newConflict("workspace owner has changed")
+		}
+		return nil, err
 	}
 
-	return s.workspaceRepo.RemoveMember(w.ID, targetUser.ID)
+	return &models.WorkspaceResponse{
+		ExternalID:      w.ExternalID,
+		Name:            w.Name,
+		Description:     w.Description,
+		OwnerExternalID: newOwner.ExternalID,
+		CreatedAt:       w.CreatedAt,
+		ModifiedAt:      w.ModifiedAt,
+	}, nil
 }
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound is returned when a key has no stored blob
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded file contents under opaque keys; implementations are picked by the
+// STORAGE_DRIVER setting. Keys are slash-separated paths such as "tasks/<task>/<attachment>".
+type BlobStore interface {
+	// Put stores everything read from r under key, replacing any previous blob
+	Put(key string, r io.Reader) error
+	// Open streams a stored blob; the caller must close it
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes a blob; deleting a missing key is not an error
+	Delete(key string) error
+}
@@ -11,16 +11,20 @@ import (
 type Claims struct {
 	ExternalID string `json:"external_id"`
 	Email      string `json:"email"`
+	SessionID  string `json:"sid,omitempty"` // refresh token family the access token was issued for
+	Unverified bool   `json:"unverified,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT token for a user
-func GenerateToken(externalID string, email string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateToken generates a short-lived JWT access token for a user session
+func GenerateToken(externalID string, email string, sessionID string, emailVerified bool) (string, error) {
+	expirationTime := time.Now().Add(config.AppConfig.AccessTokenTTL)
 
 	claims := &Claims{
 		ExternalID: externalID,
 		Email:      email,
+		SessionID:  sessionID,
+		Unverified: !emailVerified,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,128 @@
+package utils
+
+import "strings"
+
+// rankDigits are the characters of a rank key, in byte order so keys sort with plain string comparison
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// RankRebalanceLength is the key length past which a list should be re-ranked with RankSequence.
+// Repeated drops into the same gap grow keys by about one character every five drops.
+const RankRebalanceLength = 64
+
+// rankStepWidth is the width at which appended keys are counted up, leaving 36^5 appends after a
+// one-character key before it has to grow
+const rankStepWidth = 6
+
+// RankBetween returns a key that sorts strictly between lower and upper, so an item can be placed
+// between two neighbors without renumbering anything else. An empty lower means the start of the
+// list, an empty upper its end. Keys never end in '0', which guarantees there is always room
+// between two of them; callers must only pass keys produced here.
+func RankBetween(lower, upper string) string {
+	if upper == "" && lower != "" {
+		return RankAfter(lower)
+	}
+	return bisectRank(lower, upper)
+}
+
+// RankAfter returns the next key after lower. Instead of halving the space towards the end of the
+// list, it counts up at a fixed width, so appending one item after another keeps keys short.
+func RankAfter(lower string) string {
+	if lower == "" {
+		return bisectRank("", "")
+	}
+
+	width := len(lower)
+	if width < rankStepWidth {
+		width = rankStepWidth
+	}
+	key := []byte(lower + strings.Repeat(rankDigits[:1], width-len(lower)))
+	for i := width - 1; i >= 0; i-- {
+		d := strings.IndexByte(rankDigits, key[i])
+		if d < len(rankDigits)-1 {
+			key[i] = rankDigits[d+1]
+			return string(key[:i+1])
+		}
+	}
+
+	// Every position is already the last digit, so the key has to grow
+	return bisectRank(lower, "")
+}
+
+// RankSequence returns n evenly spaced keys in ascending order, used to re-rank a whole list once
+// its keys have grown too long. The gaps are wide enough for plenty of later drops and appends.
+func RankSequence(n int) []string {
+	width := rankStepWidth
+	space := int64(1)
+	for i := 0; i < width; i++ {
+		space *= int64(len(rankDigits))
+	}
+	for space/int64(n+1) < int64(len(rankDigits)*len(rankDigits)) && width < 12 {
+		width++
+		space *= int64(len(rankDigits))
+	}
+
+	step := space / int64(n+1)
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = encodeRank(int64(i+1)*step, width)
+	}
+	return keys
+}
+
+// encodeRank writes v as a base-36 key of the given width, dropping trailing zeros
+func encodeRank(v int64, width int) string {
+	key := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		key[i] = rankDigits[v%int64(len(rankDigits))]
+		v /= int64(len(rankDigits))
+	}
+	return strings.TrimRight(string(key), rankDigits[:1])
+}
+
+// bisectRank picks a key roughly halfway between lower and upper
+func bisectRank(lower, upper string) string {
+	if upper != "" {
+		// Keep the prefix both keys share, then split the first position where they differ
+		n := 0
+		for n < len(upper) && rankDigitAt(lower, n) == upper[n] {
+			n++
+		}
+		if n > 0 {
+			return upper[:n] + bisectRank(suffixFrom(lower, n), upper[n:])
+		}
+	}
+
+	low := 0
+	if lower != "" {
+		low = strings.IndexByte(rankDigits, lower[0])
+	}
+	high := len(rankDigits)
+	if upper != "" {
+		high = strings.IndexByte(rankDigits, upper[0])
+	}
+
+	if high-low > 1 {
+		return string(rankDigits[(low+high+1)/2])
+	}
+
+	// Adjacent digits: a shorter prefix of upper still sorts above lower, otherwise extend lower
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+	return string(rankDigits[low]) + bisectRank(suffixFrom(lower, 1), "")
+}
+
+// rankDigitAt reads a key as if padded with trailing zeros
+func rankDigitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return rankDigits[0]
+}
+
+func suffixFrom(key string, i int) string {
+	if i < len(key) {
+		return key[i:]
+	}
+	return ""
+}
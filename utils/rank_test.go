@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func checkRankKey(t *testing.T, key string) {
+	t.Helper()
+	if key == "" {
+		t.Fatal("empty rank key")
+	}
+	if strings.HasSuffix(key, "0") {
+		t.Fatalf("rank key %q ends in '0'", key)
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(rankDigits, key[i]) == -1 {
+			t.Fatalf("rank key %q contains %q", key, key[i])
+		}
+	}
+}
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name         string
+		lower, upper string
+	}{
+		{"empty list", "", ""},
+		{"before first", "", "i"},
+		{"before smallest key", "", "1"},
+		{"before key starting with zero", "", "01"},
+		{"after last", "i", ""},
+		{"after last digit", "z", ""},
+		{"after all last digits", "zzzzzz", ""},
+		{"wide gap", "a", "z"},
+		{"adjacent digits", "a", "b"},
+		{"adjacent with longer upper", "a", "b5"},
+		{"adjacent with longer lower", "az", "b"},
+		{"shared prefix", "abc", "abd"},
+		{"prefix of upper", "a", "a1"},
+		{"prefix of lower", "a5", "b"},
+		{"migrated keys", "0000000001i", "0000000002i"},
+		{"long keys", "i0000000000000000001", "i0000000000000000002"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RankBetween(tt.lower, tt.upper)
+			checkRankKey(t, got)
+			if tt.lower != "" && got <= tt.lower {
+				t.Errorf("RankBetween(%q, %q) = %q, want above lower", tt.lower, tt.upper, got)
+			}
+			if tt.upper != "" && got >= tt.upper {
+				t.Errorf("RankBetween(%q, %q) = %q, want below upper", tt.lower, tt.upper, got)
+			}
+		})
+	}
+}
+
+func TestRankBetweenRepeatedInserts(t *testing.T) {
+	tests := []struct {
+		name  string
+		place func(lower, upper string) (string, string)
+	}{
+		// Always drop right above the same task, so the gap keeps shrinking from above
+		{"into the same gap", func(lower, upper string) (string, string) { return lower, upper }},
+		// Always drop at the top of the column
+		{"at the top", func(_, upper string) (string, string) { return "", upper }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lower, upper := "a", "b"
+			for i := 0; i < 500; i++ {
+				l, u := tt.place(lower, upper)
+				key := RankBetween(l, u)
+				checkRankKey(t, key)
+				if (l != "" && key <= l) || key >= u {
+					t.Fatalf("insert %d: RankBetween(%q, %q) = %q out of bounds", i, l, u, key)
+				}
+				upper = key
+			}
+		})
+	}
+}
+
+func TestRankBetweenAppendsStayShort(t *testing.T) {
+	tests := []struct {
+		name  string
+		first string
+	}{
+		{"empty column", ""},
+		{"after a one-character key", "z"},
+		{"after a migrated key", "0000000001i"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last := tt.first
+			maxLen := len(tt.first)
+			if maxLen < rankStepWidth {
+				maxLen = rankStepWidth
+			}
+			for i := 0; i < 10000; i++ {
+				key := RankBetween(last, "")
+				checkRankKey(t, key)
+				if key <= last {
+					t.Fatalf("append %d: RankBetween(%q, \"\") = %q, want above lower", i, last, key)
+				}
+				if len(key) > maxLen {
+					t.Fatalf("append %d: key %q grew past %d characters", i, key, maxLen)
+				}
+				last = key
+			}
+		})
+	}
+}
+
+func TestRankAfterCarries(t *testing.T) {
+	tests := []struct {
+		lower, want string
+	}{
+		{"", "i"},
+		{"i", "i00001"},
+		{"i00001", "i00002"},
+		{"i0000z", "i0001"},
+		{"izzzzz", "j"},
+		{"zzzzzz", "zzzzzzi"},
+		{"0000000001i", "0000000001j"},
+	}
+
+	for _, tt := range tests {
+		if got := RankAfter(tt.lower); got != tt.want {
+			t.Errorf("RankAfter(%q) = %q, want %q", tt.lower, got, tt.want)
+		}
+	}
+}
+
+func TestRankSequence(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 35, 1000, 50000} {
+		keys := RankSequence(n)
+		if len(keys) != n {
+			t.Fatalf("RankSequence(%d) returned %d keys", n, len(keys))
+		}
+		for i, key := range keys {
+			checkRankKey(t, key)
+			if len(key) > RankRebalanceLength {
+				t.Fatalf("RankSequence(%d)[%d] = %q is too long", n, i, key)
+			}
+			if i > 0 && key <= keys[i-1] {
+				t.Fatalf("RankSequence(%d) not ascending at %d: %q after %q", n, i, key, keys[i-1])
+			}
+		}
+		// There must be room between neighbors and after the last key
+		for i := 1; i < len(keys); i++ {
+			if mid := RankBetween(keys[i-1], keys[i]); mid <= keys[i-1] || mid >= keys[i] {
+				t.Fatalf("no room between %q and %q", keys[i-1], keys[i])
+			}
+		}
+		if n > 0 && len(RankAfter(keys[n-1])) > RankRebalanceLength {
+			t.Fatalf("appending after %q grows past the rebalance length", keys[n-1])
+		}
+	}
+}
@@ -9,7 +9,41 @@ func SuccessResponse(c *gin.Context, statusCode int, data interface{}) {
 
 // ErrorResponse sends an error JSON response as defined in PRD
 func ErrorResponse(c *gin.Context, statusCode int, message string) {
+	ErrorResponseWithDetails(c, statusCode, errorCode(statusCode), message, nil)
+}
+
+// ErrorResponseWithDetails sends a structured error with a machine-readable code and optional field details
+func ErrorResponseWithDetails(c *gin.Context, statusCode int, code, message string, details map[string]string) {
+	body := gin.H{
+		"code":    code,
+		"message": message,
+	}
+	if len(details) > 0 {
+		body["details"] = details
+	}
 	c.JSON(statusCode, gin.H{
-		"error": message,
+		"error": body,
 	})
 }
+
+// errorCode derives a default error code from the HTTP status
+func errorCode(statusCode int) string {
+	switch statusCode {
+	case 400:
+		return "bad_request"
+	case 401:
+		return "unauthorized"
+	case 403:
+		return "forbidden"
+	case 404:
+		return "not_found"
+	case 409:
+		return "conflict"
+	case 413:
+		return "payload_too_large"
+	case 422:
+		return "validation_error"
+	default:
+		return "internal_error"
+	}
+}